@@ -0,0 +1,114 @@
+// Package apidocs serves the OpenAPI v2 documents generated from the proto
+// files of the services, converted to OpenAPI v3 as well, and an HTML page
+// to browse them.
+package apidocs
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Path is the well-known path the documents and the explorer are served
+// under.
+const Path = "/openapi/"
+
+//go:embed index.html */*/*.swagger.json
+var files embed.FS
+
+// spec is the document of a service, as generated by generate.sh.
+type spec struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// OpenAPI3URL is the document converted to OpenAPI v3.
+	OpenAPI3URL string `json:"openapi3_url"`
+	file        string
+}
+
+var specs = []spec{
+	{Name: "greet", URL: Path + "greet.swagger.json", OpenAPI3URL: Path + "greet.openapi.json", file: "greet/greetpb/greet.swagger.json"},
+	{Name: "calculator", URL: Path + "calculator.swagger.json", OpenAPI3URL: Path + "calculator.openapi.json", file: "calculator/calculatorpb/calculator.swagger.json"},
+	{Name: "blog", URL: Path + "blog.swagger.json", OpenAPI3URL: Path + "blog.openapi.json", file: "blog/blogpb/blog.swagger.json"},
+}
+
+// Handler serves under Path the explorer, the list of the documents
+// (specs.json) and the documents of each service, in OpenAPI v2
+// (<service>.swagger.json) and v3 (<service>.openapi.json).
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(Path, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == Path || r.URL.Path == Path+"index.html" {
+			serveFile(w, "index.html", "text/html; charset=utf-8")
+			return
+		}
+
+		if r.URL.Path == Path+"specs.json" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(specs)
+			return
+		}
+
+		for _, s := range specs {
+			if r.URL.Path == s.URL {
+				serveFile(w, s.file, "application/json")
+				return
+			}
+			if r.URL.Path == s.OpenAPI3URL {
+				serveOpenAPI3(w, s.file)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+
+	return mux
+}
+
+// Handles reports whether the request is for Handler.
+func Handles(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, Path)
+}
+
+// ServeFromEnv serves Handler over HTTP when the DOCS_ADDR environment
+// variable is set.
+func ServeFromEnv(log *slog.Logger) {
+	addr := os.Getenv("DOCS_ADDR")
+	if addr == "" {
+		return
+	}
+
+	go func() {
+		if err := http.ListenAndServe(addr, Handler()); err != nil {
+			log.Error("failed to serve the API documentation", slog.String("error", err.Error()))
+		}
+	}()
+}
+
+func serveFile(w http.ResponseWriter, name string, contentType string) {
+	b, err := fs.ReadFile(files, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(b)
+}
+
+// serveOpenAPI3 serves the OpenAPI v2 document name converted to v3.
+func serveOpenAPI3(w http.ResponseWriter, name string) {
+	b, err := fs.ReadFile(files, name)
+	if err == nil {
+		b, err = toOpenAPI3(b)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
@@ -0,0 +1,115 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToOpenAPI3(t *testing.T) {
+	tests := []struct {
+		name string
+		v2   string
+		// path is the JSON path of the value checked in the v3 document
+		path []string
+		want interface{}
+	}{
+		{
+			name: "version",
+			v2:   `{"swagger": "2.0", "info": {"title": "t", "version": "v"}}`,
+			path: []string{"openapi"},
+			want: "3.0.3",
+		},
+		{
+			name: "definitions",
+			v2:   `{"swagger": "2.0", "definitions": {"Blog": {"type": "object"}}}`,
+			path: []string{"components", "schemas", "Blog", "type"},
+			want: "object",
+		},
+		{
+			name: "body parameter",
+			v2:   `{"swagger": "2.0", "paths": {"/v1/blog": {"post": {"parameters": [{"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Blog"}}], "responses": {}}}}}`,
+			path: []string{"paths", "/v1/blog", "post", "requestBody", "content", "application/json", "schema", "$ref"},
+			want: "#/components/schemas/Blog",
+		},
+		{
+			name: "query parameter",
+			v2:   `{"swagger": "2.0", "paths": {"/v1/blogs": {"get": {"parameters": [{"name": "ids", "in": "query", "type": "array", "items": {"type": "string"}, "collectionFormat": "multi"}], "responses": {}}}}}`,
+			path: []string{"paths", "/v1/blogs", "get", "parameters", "0", "schema", "items", "type"},
+			want: "string",
+		},
+		{
+			name: "response",
+			v2:   `{"swagger": "2.0", "produces": ["application/json"], "paths": {"/v1/blog": {"get": {"responses": {"200": {"description": "ok", "schema": {"$ref": "#/definitions/Blog"}}}}}}}`,
+			path: []string{"paths", "/v1/blog", "get", "responses", "200", "content", "application/json", "schema", "$ref"},
+			want: "#/components/schemas/Blog",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := toOpenAPI3([]byte(tt.v2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v interface{}
+			if err := json.Unmarshal(b, &v); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range tt.path {
+				switch node := v.(type) {
+				case map[string]interface{}:
+					v = node[key]
+				case []interface{}:
+					v = node[0]
+				default:
+					t.Fatalf("no %s in %s", strings.Join(tt.path, "."), b)
+				}
+			}
+			if !reflect.DeepEqual(v, tt.want) {
+				t.Errorf("%s = %v, want %v", strings.Join(tt.path, "."), v, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerOpenAPI3(t *testing.T) {
+	h := Handler()
+
+	for _, s := range specs {
+		t.Run(s.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, s.OpenAPI3URL, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+
+			var doc struct {
+				OpenAPI    string `json:"openapi"`
+				Components struct {
+					Schemas map[string]interface{} `json:"schemas"`
+				} `json:"components"`
+			}
+			body := rec.Body.String()
+			if err := json.Unmarshal([]byte(body), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc.OpenAPI != "3.0.3" {
+				t.Errorf("openapi = %q", doc.OpenAPI)
+			}
+			if strings.Contains(body, `"in": "body"`) || strings.Contains(body, "#/definitions/") {
+				t.Error("the document has v2 body parameters or references")
+			}
+			// every reference is a schema of the components
+			for _, ref := range strings.Split(body, `"$ref": "#/components/schemas/`)[1:] {
+				name := ref[:strings.Index(ref, `"`)]
+				if _, ok := doc.Components.Schemas[name]; !ok {
+					t.Errorf("unknown schema %s", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package apidocs
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// toOpenAPI3 converts an OpenAPI v2 document, as generated by
+// protoc-gen-swagger, to OpenAPI 3.0: the definitions become the schemas
+// of the components, the body parameters the request bodies, and the
+// schemas of the other parameters and of the responses are moved under
+// their media types.
+func toOpenAPI3(v2 []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(v2, &doc); err != nil {
+		return nil, err
+	}
+
+	consumes := mediaTypes(doc["consumes"], nil)
+	produces := mediaTypes(doc["produces"], nil)
+
+	v3 := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    doc["info"],
+		"paths":   map[string]interface{}{},
+	}
+	for _, key := range []string{"tags", "externalDocs"} {
+		if v, ok := doc[key]; ok {
+			v3[key] = v
+		}
+	}
+	if definitions, ok := doc["definitions"]; ok {
+		v3["components"] = map[string]interface{}{"schemas": definitions}
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, item := range paths {
+		operations, _ := item.(map[string]interface{})
+		converted := map[string]interface{}{}
+		for verb, op := range operations {
+			if op, ok := op.(map[string]interface{}); ok {
+				converted[verb] = convertOperation(op, consumes, produces)
+			}
+		}
+		v3["paths"].(map[string]interface{})[path] = converted
+	}
+
+	return json.MarshalIndent(rewriteRefs(v3), "", "  ")
+}
+
+// convertOperation converts the parameters and the responses of an
+// operation.
+func convertOperation(op map[string]interface{}, consumes, produces []string) map[string]interface{} {
+	consumes = mediaTypes(op["consumes"], consumes)
+	produces = mediaTypes(op["produces"], produces)
+
+	converted := map[string]interface{}{}
+	for key, v := range op {
+		switch key {
+		case "consumes", "produces", "parameters", "responses":
+		default:
+			converted[key] = v
+		}
+	}
+
+	params, _ := op["parameters"].([]interface{})
+	var parameters []interface{}
+	for _, p := range params {
+		p, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if p["in"] == "body" {
+			body := map[string]interface{}{"content": content(p["schema"], consumes)}
+			for _, key := range []string{"description", "required"} {
+				if v, ok := p[key]; ok {
+					body[key] = v
+				}
+			}
+			converted["requestBody"] = body
+			continue
+		}
+		parameters = append(parameters, convertParameter(p))
+	}
+	if len(parameters) > 0 {
+		converted["parameters"] = parameters
+	}
+
+	responses := map[string]interface{}{}
+	resps, _ := op["responses"].(map[string]interface{})
+	for code, r := range resps {
+		r, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		response := map[string]interface{}{"description": r["description"]}
+		if schema, ok := r["schema"]; ok {
+			response["content"] = content(schema, produces)
+		}
+		responses[code] = response
+	}
+	converted["responses"] = responses
+	return converted
+}
+
+// convertParameter moves the type of a path or query parameter to its
+// schema.
+func convertParameter(p map[string]interface{}) map[string]interface{} {
+	param := map[string]interface{}{}
+	schema := map[string]interface{}{}
+	for key, v := range p {
+		switch key {
+		case "name", "in", "description", "required", "allowEmptyValue":
+			param[key] = v
+		case "collectionFormat":
+			// multi, the only one generated, is the default of the
+			// query parameters
+			param["explode"] = v == "multi"
+		default:
+			schema[key] = v
+		}
+	}
+	param["schema"] = schema
+	return param
+}
+
+// content returns the content of a request or response body of schema.
+func content(schema interface{}, types []string) map[string]interface{} {
+	c := map[string]interface{}{}
+	for _, t := range types {
+		c[t] = map[string]interface{}{"schema": schema}
+	}
+	return c
+}
+
+// mediaTypes returns the media types of v, or def when it is not set.
+func mediaTypes(v interface{}, def []string) []string {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		if def == nil {
+			return []string{"application/json"}
+		}
+		return def
+	}
+
+	var types []string
+	for _, t := range list {
+		if t, ok := t.(string); ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// rewriteRefs points the references to the definitions to the schemas of
+// the components.
+func rewriteRefs(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				v[key] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+				continue
+			}
+			v[key] = rewriteRefs(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = rewriteRefs(child)
+		}
+	}
+	return v
+}
@@ -2,178 +2,178 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"io/ioutil"
 	"log"
+	"os"
 
-	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
-	"google.golang.org/grpc"
+	"github.com/pjserol/tuto-grpc-go/blog/blogclient"
 )
 
 func main() {
+	addr := flag.String("addr", "localhost:50051", "address of the blog server")
+	image := flag.String("image", "", "file name of an image to download from the server")
+	out := flag.String("out", "image.jpeg", "file the image is written to")
+	flag.Parse()
 
 	fmt.Println("Client Start!")
-	cc, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+	c, err := blogclient.Dial(*addr, blogclient.OptionsFromEnv())
 	if err != nil {
 		log.Fatalf("could not connect: %v", err)
 	}
-	defer cc.Close()
+	defer c.Close()
 
-	c := blogpb.NewBlogServiceClient(cc)
+	ctx := context.Background()
 
-	id, err := createBlog(c)
+	id, err := createBlog(ctx, c)
 	if err != nil {
-		log.Printf("Error createBlog: %v", err)
+		logError("createBlog", err)
+		return
 	}
 
-	err = readBlog(c, "id123")
+	err = readBlog(ctx, c, id)
 	if err != nil {
-		log.Printf("Error readBlog: %v", err)
+		logError("readBlog", err)
 	}
 
-	err = readBlog(c, id)
+	err = updateBlog(ctx, c, id)
 	if err != nil {
-		log.Printf("Error readBlog: %v", err)
+		logError("updateBlog", err)
 	}
 
-	err = updateBlog(c, id)
+	err = listBlog(ctx, c)
 	if err != nil {
-		log.Printf("Error updateBlog: %v", err)
+		logError("listBlog", err)
 	}
 
-	err = deleteBlog(c, id)
+	err = deleteBlog(ctx, c, id)
 	if err != nil {
-		log.Printf("Error deleteBlog: %v", err)
+		logError("deleteBlog", err)
 	}
 
-	err = listBlog(c)
-	if err != nil {
-		log.Printf("Error listBlog: %v", err)
+	// the blog is deleted: NotFound
+	err = readBlog(ctx, c, id)
+	if errors.Is(err, blogclient.ErrNotFound) {
+		log.Printf("Blog %s not found, as expected", id)
+	} else if err != nil {
+		logError("readBlog", err)
 	}
 
-	myPath := "/Users/pj/Downloads/"
-	b, err := downloadImage(c, myPath+"kobe_logoo.jpeg")
-	if err != nil {
-		log.Printf("Error downloadImage: %v", err)
+	if *image != "" {
+		err = downloadImage(ctx, c, *image, *out)
+		if err != nil {
+			logError("downloadImage", err)
+		}
 	}
+}
 
-	err = ioutil.WriteFile(myPath+"test.jpeg", b, 0644)
-	if err != nil {
-		log.Printf("Error WriteFile: %v", err)
+// logError logs err with the details sent by the server.
+func logError(call string, err error) {
+	log.Printf("Error %s: %v", call, err)
+
+	var e *blogclient.Error
+	if !errors.As(err, &e) {
+		return
+	}
+	if e.ResourceType != "" {
+		log.Printf("%s %s", e.ResourceType, e.ResourceName)
+	}
+	for _, v := range e.Violations {
+		log.Printf("Field %s %s", v.Field, v.Description)
+	}
+	if e.RetryDelay > 0 {
+		log.Printf("Retry after %v", e.RetryDelay)
 	}
 }
 
-func createBlog(c blogpb.BlogServiceClient) (blogID string, err error) {
+func createBlog(ctx context.Context, c *blogclient.Client) (blogID string, err error) {
 	log.Println("\n\n--Create blog--")
 
-	blog := &blogpb.Blog{
-		AuthorId: "Tic Tac",
+	blog, err := c.Create(ctx, blogclient.Blog{
+		AuthorID: "Tic Tac",
 		Title:    "My Blog",
 		Content:  "Some content...",
-	}
-
-	res, err := c.CreateBlog(context.Background(), &blogpb.CreateBlogRequest{Blog: blog})
+	})
 	if err != nil {
 		return "", err
 	}
 
-	fmt.Printf("Blog has been created: %v", res)
+	fmt.Printf("Blog has been created: %+v\n", blog)
 
-	return res.GetBlog().GetId(), nil
+	return blog.ID, nil
 }
 
-func readBlog(c blogpb.BlogServiceClient, id string) error {
+func readBlog(ctx context.Context, c *blogclient.Client, id string) error {
 	log.Println("\n\n--Reading blog--")
 
-	res, err := c.ReadBlog(context.Background(), &blogpb.ReadBlogRequest{BlogId: id})
+	blog, err := c.Read(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Blog was read: %v \n", res)
+	fmt.Printf("Blog was read: %+v\n", blog)
 
 	return nil
 }
 
-func updateBlog(c blogpb.BlogServiceClient, id string) error {
+func updateBlog(ctx context.Context, c *blogclient.Client, id string) error {
 	log.Println("\n\n--Updating blog--")
 
-	blog := &blogpb.Blog{
-		Id:       id,
-		AuthorId: "Ping Pong",
+	blog, err := c.Update(ctx, blogclient.Blog{
+		ID:       id,
+		AuthorID: "Ping Pong",
 		Title:    "Blog PJ",
 		Content:  "This is my first blog!",
-	}
-
-	res, err := c.UpdateBlog(context.Background(), &blogpb.UpdateBlogRequest{Blog: blog})
+	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Blog was updated: %v\n", res)
+	fmt.Printf("Blog was updated: %+v\n", blog)
 
 	return nil
 }
 
-func deleteBlog(c blogpb.BlogServiceClient, id string) error {
+func deleteBlog(ctx context.Context, c *blogclient.Client, id string) error {
 	log.Println("\n\n--Deleting blog--")
-	res, err := c.DeleteBlog(context.Background(), &blogpb.DeleteBlogRequest{BlogId: id})
-	if err != nil {
+
+	if err := c.Delete(ctx, id); err != nil {
 		return err
 	}
 
-	fmt.Printf("Blog was deleted: %v \n", res)
+	fmt.Printf("Blog was deleted: %s\n", id)
 
 	return nil
 }
 
-func listBlog(c blogpb.BlogServiceClient) error {
+func listBlog(ctx context.Context, c *blogclient.Client) error {
 	log.Println("\n\n--List blog--")
 
-	stream, err := c.ListBlog(context.Background(), &blogpb.ListBlogRequest{})
-	if err != nil {
-		return err
-	}
-
-	for {
-		res, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		fmt.Println(res.GetBlog())
+	blogs := c.List(ctx)
+	for blogs.Next() {
+		fmt.Printf("%+v\n", blogs.Blog())
 	}
 
-	return nil
+	return blogs.Err()
 }
 
-func downloadImage(c blogpb.BlogServiceClient, fileName string) ([]byte, error) {
-	log.Println("\n\n--Dowanload image--")
-	stream, err := c.DownloadImage(context.TODO(), &blogpb.DownloadImageRequest{
-		FileName: fileName,
-	})
+func downloadImage(ctx context.Context, c *blogclient.Client, fileName string, out string) error {
+	log.Println("\n\n--Download image--")
+
+	f, err := os.Create(out)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var img []byte
-	for {
-		chunkResponse, err := stream.Recv()
-		if err == io.EOF {
-			log.Println("received all chunks")
-			break
-		}
-		if err != nil {
-			log.Println("err receiving chunk:", err)
-			break
-		}
-
-		img = chunkResponse.FileChunk
+	n, err := c.DownloadImage(ctx, fileName, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
 	}
 
-	return img, nil
+	log.Printf("received all chunks: %d bytes written to %s", n, out)
+	return nil
 }
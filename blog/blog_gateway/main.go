@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/pjserol/tuto-grpc-go/apidocs"
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func main() {
+	// if we crash the go code, we get the file name and line number
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	addr := flag.String("addr", "0.0.0.0:8080", "address of the HTTP server")
+	endpoint := flag.String("endpoint", "localhost:50051", "address of the blog gRPC server")
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := []grpc.DialOption{}
+	if os.Getenv("Environment") == "local" {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		certFile := "ssl/ca.crt" // Certificate Authority trust certificate
+		creds, err := credentials.NewClientTLSFromFile(certFile, "")
+		if err != nil {
+			log.Fatalf("Error while loading CA trust certificate: %v", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	}
+
+	// ListBlog is streamed as newline-delimited JSON
+	mux := runtime.NewServeMux()
+	if err := blogpb.RegisterBlogServiceHandlerFromEndpoint(ctx, mux, *endpoint, opts); err != nil {
+		log.Fatalf("Failed to register the blog gateway: %v", err)
+	}
+
+	// the API documentation is served next to the API, so that its explorer
+	// can call the blog service
+	docs := apidocs.Handler()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apidocs.Handles(r) {
+			docs.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+
+	srv := &http.Server{Addr: *addr, Handler: handler}
+
+	go func() {
+		log.Printf("Starting blog gateway on %s...", *addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("failed to serve: %v ", err)
+		}
+	}()
+
+	// Wait for Control C to exit
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+
+	// Block until a signal is received
+	<-ch
+	log.Println("Stopping blog gateway.")
+	srv.Shutdown(ctx)
+	log.Println("End of blog gateway.")
+}
@@ -1,183 +0,0 @@
-package main
-
-import (
-	"context"
-	"fmt"
-	"io"
-	"log"
-	"os"
-
-	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-)
-
-func (*server) CreateBlog(ctx context.Context, req *blogpb.CreateBlogRequest) (*blogpb.CreateBlogResponse, error) {
-	log.Println("Create blog request")
-	blog := req.GetBlog()
-
-	data := blogItem{
-		AuthorID: blog.GetAuthorId(),
-		Title:    blog.GetTitle(),
-		Content:  blog.GetContent(),
-	}
-
-	var err error
-	data, err = insertBlog(ctx, data)
-	if err != nil {
-		return nil, status.Errorf(
-			codes.Internal,
-			fmt.Sprintf("Internal error: %v", err),
-		)
-	}
-
-	return &blogpb.CreateBlogResponse{
-		Blog: dataToBlogPb(&data),
-	}, nil
-}
-
-func (*server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
-	log.Println("Read blog request")
-
-	blogID, err := primitive.ObjectIDFromHex(req.GetBlogId())
-	if err != nil {
-		return nil, status.Errorf(
-			codes.InvalidArgument,
-			fmt.Sprintf("Cannot parse ID"),
-		)
-	}
-
-	data, err := findBlogByID(ctx, blogID)
-	if err != nil {
-		return nil, status.Errorf(
-			codes.NotFound,
-			fmt.Sprintf("Cannot find blog with specified ID: %v", err),
-		)
-	}
-
-	return &blogpb.ReadBlogResponse{
-		Blog: dataToBlogPb(&data),
-	}, nil
-}
-
-func (*server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (*blogpb.UpdateBlogResponse, error) {
-	log.Println("Update blog request")
-
-	blog := req.GetBlog()
-	blogID, err := primitive.ObjectIDFromHex(blog.GetId())
-	if err != nil {
-		return nil, status.Errorf(
-			codes.InvalidArgument,
-			fmt.Sprintf("Cannot parse ID"),
-		)
-	}
-
-	data := blogItem{}
-	data.AuthorID = blog.GetAuthorId()
-	data.Content = blog.GetContent()
-	data.Title = blog.GetTitle()
-
-	item, err := updateBlog(ctx, blogID, data)
-	if err != nil {
-		return nil, status.Errorf(
-			codes.Internal,
-			fmt.Sprintf("Internal error: %v", err),
-		)
-	}
-
-	return &blogpb.UpdateBlogResponse{
-		Blog: dataToBlogPb(&item),
-	}, nil
-
-}
-
-func (*server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (*blogpb.DeleteBlogResponse, error) {
-	log.Println("Delete blog request")
-	blogID, err := primitive.ObjectIDFromHex(req.GetBlogId())
-	if err != nil {
-		return nil, status.Errorf(
-			codes.InvalidArgument,
-			fmt.Sprintf("Cannot parse ID"),
-		)
-	}
-
-	if err := deleteBlog(ctx, blogID); err != nil {
-		return nil, status.Errorf(
-			codes.Internal,
-			fmt.Sprintf("Cannot delete object in MongoDB: %v", err),
-		)
-	}
-
-	return &blogpb.DeleteBlogResponse{BlogId: req.GetBlogId()}, nil
-}
-
-func (*server) ListBlog(req *blogpb.ListBlogRequest, stream blogpb.BlogService_ListBlogServer) error {
-	log.Println("List blog request")
-
-	ctx := context.Background()
-
-	cur, err := listBlog(ctx)
-	if err != nil {
-		return status.Errorf(
-			codes.Internal,
-			fmt.Sprintf("Cannot delete object in MongoDB: %v", err),
-		)
-	}
-
-	defer cur.Close(ctx)
-
-	for cur.Next(ctx) {
-		data := &blogItem{}
-		err := cur.Decode(data)
-		if err != nil {
-			return status.Errorf(
-				codes.Internal,
-				fmt.Sprintf("Error while decoding data from MongoDB: %v", err),
-			)
-
-		}
-		stream.Send(&blogpb.ListBlogResponse{Blog: dataToBlogPb(data)})
-	}
-
-	if err := cur.Err(); err != nil {
-		return status.Errorf(
-			codes.Internal,
-			fmt.Sprintf("Unknown internal error: %v", err),
-		)
-	}
-
-	return nil
-}
-
-func (s *server) DownloadImage(req *blogpb.DownloadImageRequest, stream blogpb.BlogService_DownloadImageServer) error {
-	log.Println("Download Image request")
-	bufferSize := 64 * 1024 //64KiB, tweak this as desired
-
-	file, err := os.Open(req.GetFileName())
-	if err != nil {
-		return err
-	}
-
-	defer file.Close()
-
-	buff := make([]byte, bufferSize)
-	for {
-		bytesRead, err := file.Read(buff)
-		if err != nil {
-			if err == io.EOF {
-				return nil
-			} else if err != nil {
-				return err
-			}
-		}
-
-		resp := &blogpb.DownloadImageResponse{
-			FileChunk: buff[:bytesRead],
-		}
-
-		if err := stream.Send(resp); err != nil {
-			return err
-		}
-	}
-}
@@ -0,0 +1,457 @@
+// Package blogclient is a client of the BlogService, with methods taking
+// and returning Blog structs and typed errors:
+//
+//	c, err := blogclient.Dial("localhost:50051", blogclient.OptionsFromEnv())
+//	...
+//	defer c.Close()
+//	blog, err := c.Create(ctx, blogclient.Blog{AuthorID: "pj", Title: "My blog"})
+//
+// The errors of the methods are *Error.
+package blogclient
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultCAFile is the certificate of the CA of the servers, see
+// ssl/instructions.sh.
+const DefaultCAFile = "ssl/ca.crt"
+
+const defaultTimeout = 10 * time.Second
+
+// Options configures the Client.
+type Options struct {
+	// CAFile is the certificate of the CA trusted to authenticate the
+	// server. The connection is not encrypted when it is empty.
+	CAFile string
+	// ServerName overrides the name of the server checked in its
+	// certificate.
+	ServerName string
+	// Token is sent in the authorization metadata of the calls, as a
+	// bearer token, when it is not empty.
+	Token string
+	// Timeout is the deadline of the unary calls whose context has none,
+	// 10s when zero. The streams have no default deadline.
+	Timeout time.Duration
+	// DialOptions are added to the options of Dial.
+	DialOptions []grpc.DialOption
+}
+
+// OptionsFromEnv returns the Options of the servers run with the same
+// environment variables: TLS with DefaultCAFile, but when Environment is
+// "local", and the token of BLOG_TOKEN.
+func OptionsFromEnv() Options {
+	opts := Options{Token: os.Getenv("BLOG_TOKEN")}
+	if os.Getenv("Environment") != "local" {
+		opts.CAFile = DefaultCAFile
+	}
+	return opts
+}
+
+// Blog is a blog post.
+type Blog struct {
+	ID       string
+	AuthorID string
+	Title    string
+	Content  string
+}
+
+func fromProto(b *blogpb.Blog) Blog {
+	return Blog{
+		ID:       b.GetId(),
+		AuthorID: b.GetAuthorId(),
+		Title:    b.GetTitle(),
+		Content:  b.GetContent(),
+	}
+}
+
+func (b Blog) proto() *blogpb.Blog {
+	return &blogpb.Blog{
+		Id:       b.ID,
+		AuthorId: b.AuthorID,
+		Title:    b.Title,
+		Content:  b.Content,
+	}
+}
+
+// Client calls the BlogService. It is safe for concurrent use.
+type Client struct {
+	c    blogpb.BlogServiceClient
+	cc   *grpc.ClientConn
+	opts Options
+}
+
+// Dial connects to the BlogService served at addr.
+func Dial(addr string, opts Options) (*Client, error) {
+	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if opts.CAFile != "" {
+		creds, err := credentials.NewClientTLSFromFile(opts.CAFile, opts.ServerName)
+		if err != nil {
+			return nil, err
+		}
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+
+	cc, err := grpc.Dial(addr, append(dialOpts, opts.DialOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := New(cc, opts)
+	c.cc = cc
+	return c, nil
+}
+
+// New returns a Client of the BlogService served on cc. CAFile,
+// ServerName and DialOptions are ignored.
+func New(cc grpc.ClientConnInterface, opts Options) *Client {
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	return &Client{
+		c:    blogpb.NewBlogServiceClient(cc),
+		opts: opts,
+	}
+}
+
+// Close closes the connection opened by Dial.
+func (c *Client) Close() error {
+	if c.cc == nil {
+		return nil
+	}
+	return c.cc.Close()
+}
+
+// streamContext returns the context of a call, with the token.
+func (c *Client) streamContext(ctx context.Context) context.Context {
+	if c.opts.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.opts.Token)
+	}
+	return ctx
+}
+
+// unaryContext returns the context of a unary call, with the token and the
+// default timeout.
+func (c *Client) unaryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = c.streamContext(ctx)
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.opts.Timeout)
+}
+
+// Create creates blog, whose ID is ignored, and returns it with its ID.
+func (c *Client) Create(ctx context.Context, blog Blog) (Blog, error) {
+	ctx, cancel := c.unaryContext(ctx)
+	defer cancel()
+
+	blog.ID = ""
+	res, err := c.c.CreateBlog(ctx, &blogpb.CreateBlogRequest{Blog: blog.proto()})
+	if err != nil {
+		return Blog{}, grpcerr.ClientError(err)
+	}
+	return fromProto(res.GetBlog()), nil
+}
+
+// Read returns the blog of id, an ErrNotFound error when it does not
+// exist.
+func (c *Client) Read(ctx context.Context, id string) (Blog, error) {
+	ctx, cancel := c.unaryContext(ctx)
+	defer cancel()
+
+	res, err := c.c.ReadBlog(ctx, &blogpb.ReadBlogRequest{BlogId: id})
+	if err != nil {
+		return Blog{}, grpcerr.ClientError(err)
+	}
+	return fromProto(res.GetBlog()), nil
+}
+
+// Update replaces the blog of blog.ID, and returns it.
+func (c *Client) Update(ctx context.Context, blog Blog) (Blog, error) {
+	ctx, cancel := c.unaryContext(ctx)
+	defer cancel()
+
+	res, err := c.c.UpdateBlog(ctx, &blogpb.UpdateBlogRequest{Blog: blog.proto()})
+	if err != nil {
+		return Blog{}, grpcerr.ClientError(err)
+	}
+	return fromProto(res.GetBlog()), nil
+}
+
+// Delete deletes the blog of id, an ErrNotFound error when it does not
+// exist.
+func (c *Client) Delete(ctx context.Context, id string) error {
+	ctx, cancel := c.unaryContext(ctx)
+	defer cancel()
+
+	_, err := c.c.DeleteBlog(ctx, &blogpb.DeleteBlogRequest{BlogId: id})
+	return grpcerr.ClientError(err)
+}
+
+// Blogs iterates over the blogs streamed by List or Export:
+//
+//	blogs := c.List(ctx)
+//	for blogs.Next() {
+//		blog := blogs.Blog()
+//		...
+//	}
+//	if err := blogs.Err(); err != nil {
+//
+// Cancel ctx to stop the stream before its end.
+type Blogs struct {
+	// open opens the stream, and returns its receive function
+	open func() (func() (*blogpb.Blog, error), error)
+	recv func() (*blogpb.Blog, error)
+
+	done bool
+	blog Blog
+	err  error
+}
+
+// List returns all the blogs.
+func (c *Client) List(ctx context.Context) *Blogs {
+	return &Blogs{open: func() (func() (*blogpb.Blog, error), error) {
+		stream, err := c.c.ListBlog(c.streamContext(ctx), &blogpb.ListBlogRequest{})
+		if err != nil {
+			return nil, err
+		}
+		return func() (*blogpb.Blog, error) {
+			res, err := stream.Recv()
+			return res.GetBlog(), err
+		}, nil
+	}}
+}
+
+// Export returns the blogs of authorID, or all of them when it is empty, in
+// the order of their IDs.
+func (c *Client) Export(ctx context.Context, authorID string) *Blogs {
+	return &Blogs{open: func() (func() (*blogpb.Blog, error), error) {
+		stream, err := c.c.ExportBlogs(c.streamContext(ctx), &blogpb.ExportBlogsRequest{AuthorId: authorID})
+		if err != nil {
+			return nil, err
+		}
+		return func() (*blogpb.Blog, error) {
+			res, err := stream.Recv()
+			return res.GetBlog(), err
+		}, nil
+	}}
+}
+
+// Next receives the next blog, and reports whether there is one.
+func (b *Blogs) Next() bool {
+	if b.done {
+		return false
+	}
+
+	var err error
+	if b.recv == nil {
+		b.recv, err = b.open()
+	}
+	var blog *blogpb.Blog
+	if err == nil {
+		blog, err = b.recv()
+	}
+	if err == io.EOF {
+		b.done = true
+		return false
+	} else if err != nil {
+		b.done = true
+		b.err = grpcerr.ClientError(err)
+		return false
+	}
+
+	b.blog = fromProto(blog)
+	return true
+}
+
+// Blog returns the blog received by Next.
+func (b *Blogs) Blog() Blog {
+	return b.blog
+}
+
+// Err returns the error that ended the iteration, nil at the end of the
+// blogs.
+func (b *Blogs) Err() error {
+	return b.err
+}
+
+// DownloadImage writes to w the image fileName of the server, and returns
+// the number of bytes written. On error, w may hold a part of the image.
+func (c *Client) DownloadImage(ctx context.Context, fileName string, w io.Writer) (int64, error) {
+	// canceled to stop the stream when w fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.c.DownloadImage(c.streamContext(ctx), &blogpb.DownloadImageRequest{FileName: fileName})
+	if err != nil {
+		return 0, grpcerr.ClientError(err)
+	}
+
+	var written int64
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return written, nil
+		} else if err != nil {
+			return written, grpcerr.ClientError(err)
+		}
+
+		n, err := w.Write(res.GetFileChunk())
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// ImportResult is the result of Import.
+type ImportResult struct {
+	Created int
+	Updated int
+	Failed  int
+	// Errors are the errors of the first 1000 blogs failed.
+	Errors []ImportError
+	DryRun bool
+}
+
+// ImportError is the error of a blog not imported.
+type ImportError struct {
+	// Index is the index of the blog in those sent, from 0.
+	Index int
+	ID    string
+	// Err is an *Error.
+	Err error
+}
+
+// Import creates the blogs received on blogs without ID, and creates or
+// replaces those with an ID, until blogs is closed. In a dry run, the blogs
+// are checked and counted but not written. The errors of the blogs are in
+// the result: Import only fails when the whole import does, e.g. when the
+// database is unavailable.
+func (c *Client) Import(ctx context.Context, blogs <-chan Blog, dryRun bool) (ImportResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.c.ImportBlogs(c.streamContext(ctx))
+	if err != nil {
+		return ImportResult{}, grpcerr.ClientError(err)
+	}
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			return ImportResult{}, grpcerr.ClientError(status.FromContextError(ctx.Err()).Err())
+		case blog, ok := <-blogs:
+			if !ok {
+				break loop
+			}
+			err := stream.Send(&blogpb.ImportBlogsRequest{Blog: blog.proto(), DryRun: dryRun})
+			if err == io.EOF {
+				// the server ended the call, CloseAndRecv returns its error
+				break loop
+			} else if err != nil {
+				return ImportResult{}, grpcerr.ClientError(err)
+			}
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return ImportResult{}, grpcerr.ClientError(err)
+	}
+
+	result := ImportResult{
+		Created: int(res.GetCreated()),
+		Updated: int(res.GetUpdated()),
+		Failed:  int(res.GetFailed()),
+		DryRun:  res.GetDryRun(),
+	}
+	for _, e := range res.GetErrors() {
+		result.Errors = append(result.Errors, ImportError{
+			Index: int(e.GetIndex()),
+			ID:    e.GetBlogId(),
+			Err:   grpcerr.ClientError(status.FromProto(e.GetError()).Err()),
+		})
+	}
+	return result, nil
+}
+
+// BatchResult is the result of a blog of a batch.
+type BatchResult struct {
+	// Blog is the blog created or read, zero for the deletes and the
+	// errors.
+	Blog Blog
+	ID   string
+	// Err is an *Error, nil when the blog succeeded.
+	Err error
+}
+
+func batchResults(results []*blogpb.BlogResult) []BatchResult {
+	batch := make([]BatchResult, len(results))
+	for i, r := range results {
+		batch[i] = BatchResult{Blog: fromProto(r.GetBlog()), ID: r.GetBlogId()}
+		if r.GetError() != nil {
+			batch[i].Err = grpcerr.ClientError(status.FromProto(r.GetError()).Err())
+		}
+	}
+	return batch
+}
+
+// BatchCreate creates up to blogpb.MaxBatchSize blogs, whose IDs are
+// ignored, and returns their results in the same order. When atomic, the
+// blogs are all created or none of them, and the error of the first blog
+// failed is returned.
+func (c *Client) BatchCreate(ctx context.Context, blogs []Blog, atomic bool) ([]BatchResult, error) {
+	ctx, cancel := c.unaryContext(ctx)
+	defer cancel()
+
+	req := &blogpb.BatchCreateBlogsRequest{Atomic: atomic}
+	for _, b := range blogs {
+		req.Blogs = append(req.Blogs, b.proto())
+	}
+	res, err := c.c.BatchCreateBlogs(ctx, req)
+	if err != nil {
+		return nil, grpcerr.ClientError(err)
+	}
+	return batchResults(res.GetResults()), nil
+}
+
+// BatchGet reads up to blogpb.MaxBatchSize blogs, and returns their
+// results in the order of ids, an ErrNotFound error for those that do not
+// exist.
+func (c *Client) BatchGet(ctx context.Context, ids []string) ([]BatchResult, error) {
+	ctx, cancel := c.unaryContext(ctx)
+	defer cancel()
+
+	res, err := c.c.BatchGetBlogs(ctx, &blogpb.BatchGetBlogsRequest{BlogIds: ids})
+	if err != nil {
+		return nil, grpcerr.ClientError(err)
+	}
+	return batchResults(res.GetResults()), nil
+}
+
+// BatchDelete deletes up to blogpb.MaxBatchSize blogs, and returns their
+// results in the order of ids. When atomic, the blogs are all deleted or
+// none of them, as with BatchCreate.
+func (c *Client) BatchDelete(ctx context.Context, ids []string, atomic bool) ([]BatchResult, error) {
+	ctx, cancel := c.unaryContext(ctx)
+	defer cancel()
+
+	res, err := c.c.BatchDeleteBlogs(ctx, &blogpb.BatchDeleteBlogsRequest{BlogIds: ids, Atomic: atomic})
+	if err != nil {
+		return nil, grpcerr.ClientError(err)
+	}
+	return batchResults(res.GetResults()), nil
+}
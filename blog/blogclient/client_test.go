@@ -0,0 +1,290 @@
+package blogclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// blogService answers the calls with blogs, or fails them with err.
+type blogService struct {
+	blogpb.BlogServiceClient
+	blogs []*blogpb.Blog
+	err   error
+	// streamErr ends the streams after the blogs, io.EOF when nil
+	streamErr error
+
+	// ctx is the context of the last call
+	ctx context.Context
+}
+
+func (s *blogService) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest, opts ...grpc.CallOption) (*blogpb.ReadBlogResponse, error) {
+	s.ctx = ctx
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &blogpb.ReadBlogResponse{Blog: s.blogs[0]}, nil
+}
+
+func (s *blogService) ListBlog(ctx context.Context, req *blogpb.ListBlogRequest, opts ...grpc.CallOption) (blogpb.BlogService_ListBlogClient, error) {
+	s.ctx = ctx
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &listStream{blogs: s.blogs, err: s.streamErr}, nil
+}
+
+func (s *blogService) DownloadImage(ctx context.Context, req *blogpb.DownloadImageRequest, opts ...grpc.CallOption) (blogpb.BlogService_DownloadImageClient, error) {
+	s.ctx = ctx
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &imageStream{chunks: []string{"abc", "def"}, err: s.streamErr}, nil
+}
+
+func (s *blogService) BatchGetBlogs(ctx context.Context, req *blogpb.BatchGetBlogsRequest, opts ...grpc.CallOption) (*blogpb.BatchGetBlogsResponse, error) {
+	s.ctx = ctx
+	if s.err != nil {
+		return nil, s.err
+	}
+	res := &blogpb.BatchGetBlogsResponse{}
+	for _, b := range s.blogs {
+		res.Results = append(res.Results, &blogpb.BlogResult{Blog: b, BlogId: b.GetId()})
+	}
+	res.Results = append(res.Results, &blogpb.BlogResult{
+		BlogId: "unknown",
+		Error:  status.Convert(grpcerr.NotFound("blog", "unknown")).Proto(),
+	})
+	return res, nil
+}
+
+type listStream struct {
+	grpc.ClientStream
+	blogs []*blogpb.Blog
+	err   error
+}
+
+func (s *listStream) Recv() (*blogpb.ListBlogResponse, error) {
+	if len(s.blogs) == 0 {
+		if s.err == nil {
+			return nil, io.EOF
+		}
+		return nil, s.err
+	}
+	blog := s.blogs[0]
+	s.blogs = s.blogs[1:]
+	return &blogpb.ListBlogResponse{Blog: blog}, nil
+}
+
+type imageStream struct {
+	grpc.ClientStream
+	chunks []string
+	err    error
+}
+
+func (s *imageStream) Recv() (*blogpb.DownloadImageResponse, error) {
+	if len(s.chunks) == 0 {
+		if s.err == nil {
+			return nil, io.EOF
+		}
+		return nil, s.err
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return &blogpb.DownloadImageResponse{FileChunk: []byte(chunk)}, nil
+}
+
+// failingWriter fails after n bytes.
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errors.New("disk full")
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func newClient(s *blogService, opts Options) *Client {
+	c := New(nil, opts)
+	c.c = s
+	return c
+}
+
+func TestUnaryContext(t *testing.T) {
+	blog := &blogpb.Blog{Id: "1", AuthorId: "pj", Title: "title"}
+
+	tests := []struct {
+		name  string
+		opts  Options
+		ctx   func() (context.Context, context.CancelFunc)
+		token string
+		// wantTimeout is the timeout of the call
+		wantTimeout time.Duration
+	}{
+		{
+			name:        "default timeout",
+			ctx:         func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			wantTimeout: defaultTimeout,
+		},
+		{
+			name:        "timeout and token",
+			opts:        Options{Timeout: time.Second, Token: "secret"},
+			ctx:         func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			token:       "Bearer secret",
+			wantTimeout: time.Second,
+		},
+		{
+			name: "deadline of the caller",
+			opts: Options{Timeout: time.Second},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Hour)
+			},
+			wantTimeout: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &blogService{blogs: []*blogpb.Blog{blog}}
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			got, err := newClient(s, tt.opts).Read(ctx, "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != (Blog{ID: "1", AuthorID: "pj", Title: "title"}) {
+				t.Errorf("Read() = %+v", got)
+			}
+
+			deadline, ok := s.ctx.Deadline()
+			if timeout := time.Until(deadline); !ok || timeout > tt.wantTimeout || timeout < tt.wantTimeout/2 {
+				t.Errorf("deadline in %v, want %v", timeout, tt.wantTimeout)
+			}
+			md, _ := metadata.FromOutgoingContext(s.ctx)
+			if got := strings.Join(md.Get("authorization"), ", "); got != tt.token {
+				t.Errorf("authorization = %v, want %q", got, tt.token)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		want      error
+		wantDelay time.Duration
+	}{
+		{name: "not found", err: grpcerr.NotFound("blog", "1"), want: ErrNotFound},
+		{name: "unavailable", err: grpcerr.Unavailable("The database is unavailable", 5*time.Second), want: ErrUnavailable, wantDelay: 5 * time.Second},
+		{name: "conflict", err: grpcerr.Aborted("The blog was modified concurrently", time.Second), want: ErrConflict, wantDelay: time.Second},
+		{name: "deadline", err: status.FromContextError(context.DeadlineExceeded).Err(), want: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newClient(&blogService{err: tt.err}, Options{}).Read(context.Background(), "1")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Read() = %v, want %v", err, tt.want)
+			}
+			var e *Error
+			if !errors.As(err, &e) || e.RetryDelay != tt.wantDelay {
+				t.Errorf("Read() = %#v, want the retry delay %v", err, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	blogs := []*blogpb.Blog{{Id: "1"}, {Id: "2"}}
+
+	tests := []struct {
+		name    string
+		s       *blogService
+		want    int
+		wantErr error
+	}{
+		{name: "all the blogs", s: &blogService{blogs: blogs}, want: 2},
+		{name: "stream failed", s: &blogService{blogs: blogs, streamErr: grpcerr.Unavailable("unavailable", 0)}, want: 2, wantErr: ErrUnavailable},
+		{name: "call failed", s: &blogService{err: status.Error(codes.Unauthenticated, "no token")}, wantErr: ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := newClient(tt.s, Options{Token: "secret"}).List(context.Background())
+
+			n := 0
+			for list.Next() {
+				if id := list.Blog().ID; id != blogs[n].GetId() {
+					t.Errorf("blog %d: ID %q", n, id)
+				}
+				n++
+			}
+			if n != tt.want {
+				t.Errorf("%d blogs, want %d", n, tt.want)
+			}
+			if err := list.Err(); err != tt.wantErr && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+			}
+			if list.Next() {
+				t.Error("Next() after the end = true")
+			}
+			// the streams have no default deadline
+			if _, ok := tt.s.ctx.Deadline(); ok {
+				t.Error("the stream has a deadline")
+			}
+		})
+	}
+}
+
+func TestDownloadImage(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := newClient(&blogService{}, Options{}).DownloadImage(context.Background(), "image.png", &buf)
+	if err != nil || n != 6 || buf.String() != "abcdef" {
+		t.Errorf("DownloadImage() = %d, %v, wrote %q", n, err, buf.String())
+	}
+
+	s := &blogService{}
+	n, err = newClient(s, Options{}).DownloadImage(context.Background(), "image.png", &failingWriter{n: 4})
+	if err == nil || n != 4 {
+		t.Errorf("DownloadImage() to a failing writer = %d, %v", n, err)
+	}
+	// the stream is canceled when the writer fails
+	if s.ctx.Err() != context.Canceled {
+		t.Errorf("stream context error = %v, want %v", s.ctx.Err(), context.Canceled)
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	s := &blogService{blogs: []*blogpb.Blog{{Id: "1", Title: "title"}}}
+	results, err := newClient(s, Options{}).BatchGet(context.Background(), []string{"1", "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("%d results, want 2", len(results))
+	}
+	if r := results[0]; r.Err != nil || r.ID != "1" || r.Blog.Title != "title" {
+		t.Errorf("result 0 = %+v", r)
+	}
+	if r := results[1]; !errors.Is(r.Err, ErrNotFound) || r.ID != "unknown" || r.Blog != (Blog{}) {
+		t.Errorf("result 1 = %+v, want %v", r, ErrNotFound)
+	}
+}
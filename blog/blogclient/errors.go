@@ -0,0 +1,29 @@
+package blogclient
+
+import (
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/codes"
+)
+
+// Errors matched by errors.Is on their Code, e.g.
+//
+//	if errors.Is(err, blogclient.ErrNotFound) {
+var (
+	ErrNotFound        = &Error{Code: codes.NotFound}
+	ErrAlreadyExists   = &Error{Code: codes.AlreadyExists}
+	ErrInvalidArgument = &Error{Code: codes.InvalidArgument}
+	// ErrConflict is the error of an update of a blog modified
+	// concurrently, to retry after RetryDelay.
+	ErrConflict = &Error{Code: codes.Aborted}
+	// ErrUnavailable is the error of a call to retry after RetryDelay, when
+	// the server or its database is unavailable.
+	ErrUnavailable       = &Error{Code: codes.Unavailable}
+	ErrResourceExhausted = &Error{Code: codes.ResourceExhausted}
+	ErrUnauthenticated   = &Error{Code: codes.Unauthenticated}
+)
+
+// Error is the error of a call, with the details sent by the server.
+type Error = grpcerr.Error
+
+// Violation is a field of the request and why it was rejected.
+type Violation = grpcerr.Violation
@@ -0,0 +1,406 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: blog/blogpb/blog.proto
+
+package blogpbconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	blogpb "github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// BlogServiceName is the fully-qualified name of the BlogService service.
+	BlogServiceName = "blog.BlogService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// BlogServiceCreateBlogProcedure is the fully-qualified name of the BlogService's CreateBlog RPC.
+	BlogServiceCreateBlogProcedure = "/blog.BlogService/CreateBlog"
+	// BlogServiceReadBlogProcedure is the fully-qualified name of the BlogService's ReadBlog RPC.
+	BlogServiceReadBlogProcedure = "/blog.BlogService/ReadBlog"
+	// BlogServiceUpdateBlogProcedure is the fully-qualified name of the BlogService's UpdateBlog RPC.
+	BlogServiceUpdateBlogProcedure = "/blog.BlogService/UpdateBlog"
+	// BlogServiceDeleteBlogProcedure is the fully-qualified name of the BlogService's DeleteBlog RPC.
+	BlogServiceDeleteBlogProcedure = "/blog.BlogService/DeleteBlog"
+	// BlogServiceListBlogProcedure is the fully-qualified name of the BlogService's ListBlog RPC.
+	BlogServiceListBlogProcedure = "/blog.BlogService/ListBlog"
+	// BlogServiceDownloadImageProcedure is the fully-qualified name of the BlogService's DownloadImage
+	// RPC.
+	BlogServiceDownloadImageProcedure = "/blog.BlogService/DownloadImage"
+	// BlogServiceImportBlogsProcedure is the fully-qualified name of the BlogService's ImportBlogs RPC.
+	BlogServiceImportBlogsProcedure = "/blog.BlogService/ImportBlogs"
+	// BlogServiceExportBlogsProcedure is the fully-qualified name of the BlogService's ExportBlogs RPC.
+	BlogServiceExportBlogsProcedure = "/blog.BlogService/ExportBlogs"
+	// BlogServiceBatchCreateBlogsProcedure is the fully-qualified name of the BlogService's
+	// BatchCreateBlogs RPC.
+	BlogServiceBatchCreateBlogsProcedure = "/blog.BlogService/BatchCreateBlogs"
+	// BlogServiceBatchGetBlogsProcedure is the fully-qualified name of the BlogService's BatchGetBlogs
+	// RPC.
+	BlogServiceBatchGetBlogsProcedure = "/blog.BlogService/BatchGetBlogs"
+	// BlogServiceBatchDeleteBlogsProcedure is the fully-qualified name of the BlogService's
+	// BatchDeleteBlogs RPC.
+	BlogServiceBatchDeleteBlogsProcedure = "/blog.BlogService/BatchDeleteBlogs"
+)
+
+// BlogServiceClient is a client for the blog.BlogService service.
+type BlogServiceClient interface {
+	CreateBlog(context.Context, *connect.Request[blogpb.CreateBlogRequest]) (*connect.Response[blogpb.CreateBlogResponse], error)
+	ReadBlog(context.Context, *connect.Request[blogpb.ReadBlogRequest]) (*connect.Response[blogpb.ReadBlogResponse], error)
+	UpdateBlog(context.Context, *connect.Request[blogpb.UpdateBlogRequest]) (*connect.Response[blogpb.UpdateBlogResponse], error)
+	DeleteBlog(context.Context, *connect.Request[blogpb.DeleteBlogRequest]) (*connect.Response[blogpb.DeleteBlogResponse], error)
+	// Streamed as newline-delimited JSON by the gateway
+	ListBlog(context.Context, *connect.Request[blogpb.ListBlogRequest]) (*connect.ServerStreamForClient[blogpb.ListBlogResponse], error)
+	DownloadImage(context.Context, *connect.Request[blogpb.DownloadImageRequest]) (*connect.ServerStreamForClient[blogpb.DownloadImageResponse], error)
+	// Imports the blogs, reporting the errors by blog: the import only fails
+	// when the database is unavailable
+	ImportBlogs(context.Context) *connect.ClientStreamForClient[blogpb.ImportBlogsRequest, blogpb.ImportBlogsResponse]
+	// Exports the blogs in the order of their ids
+	ExportBlogs(context.Context, *connect.Request[blogpb.ExportBlogsRequest]) (*connect.ServerStreamForClient[blogpb.ExportBlogsResponse], error)
+	// The batches return the result of each blog: they only fail when the
+	// request is invalid, the database is unavailable, or a blog fails in an
+	// atomic batch
+	BatchCreateBlogs(context.Context, *connect.Request[blogpb.BatchCreateBlogsRequest]) (*connect.Response[blogpb.BatchCreateBlogsResponse], error)
+	BatchGetBlogs(context.Context, *connect.Request[blogpb.BatchGetBlogsRequest]) (*connect.Response[blogpb.BatchGetBlogsResponse], error)
+	BatchDeleteBlogs(context.Context, *connect.Request[blogpb.BatchDeleteBlogsRequest]) (*connect.Response[blogpb.BatchDeleteBlogsResponse], error)
+}
+
+// NewBlogServiceClient constructs a client for the blog.BlogService service. By default, it uses
+// the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewBlogServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) BlogServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	blogServiceMethods := blogpb.File_blog_blogpb_blog_proto.Services().ByName("BlogService").Methods()
+	return &blogServiceClient{
+		createBlog: connect.NewClient[blogpb.CreateBlogRequest, blogpb.CreateBlogResponse](
+			httpClient,
+			baseURL+BlogServiceCreateBlogProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("CreateBlog")),
+			connect.WithClientOptions(opts...),
+		),
+		readBlog: connect.NewClient[blogpb.ReadBlogRequest, blogpb.ReadBlogResponse](
+			httpClient,
+			baseURL+BlogServiceReadBlogProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("ReadBlog")),
+			connect.WithClientOptions(opts...),
+		),
+		updateBlog: connect.NewClient[blogpb.UpdateBlogRequest, blogpb.UpdateBlogResponse](
+			httpClient,
+			baseURL+BlogServiceUpdateBlogProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("UpdateBlog")),
+			connect.WithClientOptions(opts...),
+		),
+		deleteBlog: connect.NewClient[blogpb.DeleteBlogRequest, blogpb.DeleteBlogResponse](
+			httpClient,
+			baseURL+BlogServiceDeleteBlogProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("DeleteBlog")),
+			connect.WithClientOptions(opts...),
+		),
+		listBlog: connect.NewClient[blogpb.ListBlogRequest, blogpb.ListBlogResponse](
+			httpClient,
+			baseURL+BlogServiceListBlogProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("ListBlog")),
+			connect.WithClientOptions(opts...),
+		),
+		downloadImage: connect.NewClient[blogpb.DownloadImageRequest, blogpb.DownloadImageResponse](
+			httpClient,
+			baseURL+BlogServiceDownloadImageProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("DownloadImage")),
+			connect.WithClientOptions(opts...),
+		),
+		importBlogs: connect.NewClient[blogpb.ImportBlogsRequest, blogpb.ImportBlogsResponse](
+			httpClient,
+			baseURL+BlogServiceImportBlogsProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("ImportBlogs")),
+			connect.WithClientOptions(opts...),
+		),
+		exportBlogs: connect.NewClient[blogpb.ExportBlogsRequest, blogpb.ExportBlogsResponse](
+			httpClient,
+			baseURL+BlogServiceExportBlogsProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("ExportBlogs")),
+			connect.WithClientOptions(opts...),
+		),
+		batchCreateBlogs: connect.NewClient[blogpb.BatchCreateBlogsRequest, blogpb.BatchCreateBlogsResponse](
+			httpClient,
+			baseURL+BlogServiceBatchCreateBlogsProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("BatchCreateBlogs")),
+			connect.WithClientOptions(opts...),
+		),
+		batchGetBlogs: connect.NewClient[blogpb.BatchGetBlogsRequest, blogpb.BatchGetBlogsResponse](
+			httpClient,
+			baseURL+BlogServiceBatchGetBlogsProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("BatchGetBlogs")),
+			connect.WithClientOptions(opts...),
+		),
+		batchDeleteBlogs: connect.NewClient[blogpb.BatchDeleteBlogsRequest, blogpb.BatchDeleteBlogsResponse](
+			httpClient,
+			baseURL+BlogServiceBatchDeleteBlogsProcedure,
+			connect.WithSchema(blogServiceMethods.ByName("BatchDeleteBlogs")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// blogServiceClient implements BlogServiceClient.
+type blogServiceClient struct {
+	createBlog       *connect.Client[blogpb.CreateBlogRequest, blogpb.CreateBlogResponse]
+	readBlog         *connect.Client[blogpb.ReadBlogRequest, blogpb.ReadBlogResponse]
+	updateBlog       *connect.Client[blogpb.UpdateBlogRequest, blogpb.UpdateBlogResponse]
+	deleteBlog       *connect.Client[blogpb.DeleteBlogRequest, blogpb.DeleteBlogResponse]
+	listBlog         *connect.Client[blogpb.ListBlogRequest, blogpb.ListBlogResponse]
+	downloadImage    *connect.Client[blogpb.DownloadImageRequest, blogpb.DownloadImageResponse]
+	importBlogs      *connect.Client[blogpb.ImportBlogsRequest, blogpb.ImportBlogsResponse]
+	exportBlogs      *connect.Client[blogpb.ExportBlogsRequest, blogpb.ExportBlogsResponse]
+	batchCreateBlogs *connect.Client[blogpb.BatchCreateBlogsRequest, blogpb.BatchCreateBlogsResponse]
+	batchGetBlogs    *connect.Client[blogpb.BatchGetBlogsRequest, blogpb.BatchGetBlogsResponse]
+	batchDeleteBlogs *connect.Client[blogpb.BatchDeleteBlogsRequest, blogpb.BatchDeleteBlogsResponse]
+}
+
+// CreateBlog calls blog.BlogService.CreateBlog.
+func (c *blogServiceClient) CreateBlog(ctx context.Context, req *connect.Request[blogpb.CreateBlogRequest]) (*connect.Response[blogpb.CreateBlogResponse], error) {
+	return c.createBlog.CallUnary(ctx, req)
+}
+
+// ReadBlog calls blog.BlogService.ReadBlog.
+func (c *blogServiceClient) ReadBlog(ctx context.Context, req *connect.Request[blogpb.ReadBlogRequest]) (*connect.Response[blogpb.ReadBlogResponse], error) {
+	return c.readBlog.CallUnary(ctx, req)
+}
+
+// UpdateBlog calls blog.BlogService.UpdateBlog.
+func (c *blogServiceClient) UpdateBlog(ctx context.Context, req *connect.Request[blogpb.UpdateBlogRequest]) (*connect.Response[blogpb.UpdateBlogResponse], error) {
+	return c.updateBlog.CallUnary(ctx, req)
+}
+
+// DeleteBlog calls blog.BlogService.DeleteBlog.
+func (c *blogServiceClient) DeleteBlog(ctx context.Context, req *connect.Request[blogpb.DeleteBlogRequest]) (*connect.Response[blogpb.DeleteBlogResponse], error) {
+	return c.deleteBlog.CallUnary(ctx, req)
+}
+
+// ListBlog calls blog.BlogService.ListBlog.
+func (c *blogServiceClient) ListBlog(ctx context.Context, req *connect.Request[blogpb.ListBlogRequest]) (*connect.ServerStreamForClient[blogpb.ListBlogResponse], error) {
+	return c.listBlog.CallServerStream(ctx, req)
+}
+
+// DownloadImage calls blog.BlogService.DownloadImage.
+func (c *blogServiceClient) DownloadImage(ctx context.Context, req *connect.Request[blogpb.DownloadImageRequest]) (*connect.ServerStreamForClient[blogpb.DownloadImageResponse], error) {
+	return c.downloadImage.CallServerStream(ctx, req)
+}
+
+// ImportBlogs calls blog.BlogService.ImportBlogs.
+func (c *blogServiceClient) ImportBlogs(ctx context.Context) *connect.ClientStreamForClient[blogpb.ImportBlogsRequest, blogpb.ImportBlogsResponse] {
+	return c.importBlogs.CallClientStream(ctx)
+}
+
+// ExportBlogs calls blog.BlogService.ExportBlogs.
+func (c *blogServiceClient) ExportBlogs(ctx context.Context, req *connect.Request[blogpb.ExportBlogsRequest]) (*connect.ServerStreamForClient[blogpb.ExportBlogsResponse], error) {
+	return c.exportBlogs.CallServerStream(ctx, req)
+}
+
+// BatchCreateBlogs calls blog.BlogService.BatchCreateBlogs.
+func (c *blogServiceClient) BatchCreateBlogs(ctx context.Context, req *connect.Request[blogpb.BatchCreateBlogsRequest]) (*connect.Response[blogpb.BatchCreateBlogsResponse], error) {
+	return c.batchCreateBlogs.CallUnary(ctx, req)
+}
+
+// BatchGetBlogs calls blog.BlogService.BatchGetBlogs.
+func (c *blogServiceClient) BatchGetBlogs(ctx context.Context, req *connect.Request[blogpb.BatchGetBlogsRequest]) (*connect.Response[blogpb.BatchGetBlogsResponse], error) {
+	return c.batchGetBlogs.CallUnary(ctx, req)
+}
+
+// BatchDeleteBlogs calls blog.BlogService.BatchDeleteBlogs.
+func (c *blogServiceClient) BatchDeleteBlogs(ctx context.Context, req *connect.Request[blogpb.BatchDeleteBlogsRequest]) (*connect.Response[blogpb.BatchDeleteBlogsResponse], error) {
+	return c.batchDeleteBlogs.CallUnary(ctx, req)
+}
+
+// BlogServiceHandler is an implementation of the blog.BlogService service.
+type BlogServiceHandler interface {
+	CreateBlog(context.Context, *connect.Request[blogpb.CreateBlogRequest]) (*connect.Response[blogpb.CreateBlogResponse], error)
+	ReadBlog(context.Context, *connect.Request[blogpb.ReadBlogRequest]) (*connect.Response[blogpb.ReadBlogResponse], error)
+	UpdateBlog(context.Context, *connect.Request[blogpb.UpdateBlogRequest]) (*connect.Response[blogpb.UpdateBlogResponse], error)
+	DeleteBlog(context.Context, *connect.Request[blogpb.DeleteBlogRequest]) (*connect.Response[blogpb.DeleteBlogResponse], error)
+	// Streamed as newline-delimited JSON by the gateway
+	ListBlog(context.Context, *connect.Request[blogpb.ListBlogRequest], *connect.ServerStream[blogpb.ListBlogResponse]) error
+	DownloadImage(context.Context, *connect.Request[blogpb.DownloadImageRequest], *connect.ServerStream[blogpb.DownloadImageResponse]) error
+	// Imports the blogs, reporting the errors by blog: the import only fails
+	// when the database is unavailable
+	ImportBlogs(context.Context, *connect.ClientStream[blogpb.ImportBlogsRequest]) (*connect.Response[blogpb.ImportBlogsResponse], error)
+	// Exports the blogs in the order of their ids
+	ExportBlogs(context.Context, *connect.Request[blogpb.ExportBlogsRequest], *connect.ServerStream[blogpb.ExportBlogsResponse]) error
+	// The batches return the result of each blog: they only fail when the
+	// request is invalid, the database is unavailable, or a blog fails in an
+	// atomic batch
+	BatchCreateBlogs(context.Context, *connect.Request[blogpb.BatchCreateBlogsRequest]) (*connect.Response[blogpb.BatchCreateBlogsResponse], error)
+	BatchGetBlogs(context.Context, *connect.Request[blogpb.BatchGetBlogsRequest]) (*connect.Response[blogpb.BatchGetBlogsResponse], error)
+	BatchDeleteBlogs(context.Context, *connect.Request[blogpb.BatchDeleteBlogsRequest]) (*connect.Response[blogpb.BatchDeleteBlogsResponse], error)
+}
+
+// NewBlogServiceHandler builds an HTTP handler from the service implementation. It returns the path
+// on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewBlogServiceHandler(svc BlogServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	blogServiceMethods := blogpb.File_blog_blogpb_blog_proto.Services().ByName("BlogService").Methods()
+	blogServiceCreateBlogHandler := connect.NewUnaryHandler(
+		BlogServiceCreateBlogProcedure,
+		svc.CreateBlog,
+		connect.WithSchema(blogServiceMethods.ByName("CreateBlog")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceReadBlogHandler := connect.NewUnaryHandler(
+		BlogServiceReadBlogProcedure,
+		svc.ReadBlog,
+		connect.WithSchema(blogServiceMethods.ByName("ReadBlog")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceUpdateBlogHandler := connect.NewUnaryHandler(
+		BlogServiceUpdateBlogProcedure,
+		svc.UpdateBlog,
+		connect.WithSchema(blogServiceMethods.ByName("UpdateBlog")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceDeleteBlogHandler := connect.NewUnaryHandler(
+		BlogServiceDeleteBlogProcedure,
+		svc.DeleteBlog,
+		connect.WithSchema(blogServiceMethods.ByName("DeleteBlog")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceListBlogHandler := connect.NewServerStreamHandler(
+		BlogServiceListBlogProcedure,
+		svc.ListBlog,
+		connect.WithSchema(blogServiceMethods.ByName("ListBlog")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceDownloadImageHandler := connect.NewServerStreamHandler(
+		BlogServiceDownloadImageProcedure,
+		svc.DownloadImage,
+		connect.WithSchema(blogServiceMethods.ByName("DownloadImage")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceImportBlogsHandler := connect.NewClientStreamHandler(
+		BlogServiceImportBlogsProcedure,
+		svc.ImportBlogs,
+		connect.WithSchema(blogServiceMethods.ByName("ImportBlogs")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceExportBlogsHandler := connect.NewServerStreamHandler(
+		BlogServiceExportBlogsProcedure,
+		svc.ExportBlogs,
+		connect.WithSchema(blogServiceMethods.ByName("ExportBlogs")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceBatchCreateBlogsHandler := connect.NewUnaryHandler(
+		BlogServiceBatchCreateBlogsProcedure,
+		svc.BatchCreateBlogs,
+		connect.WithSchema(blogServiceMethods.ByName("BatchCreateBlogs")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceBatchGetBlogsHandler := connect.NewUnaryHandler(
+		BlogServiceBatchGetBlogsProcedure,
+		svc.BatchGetBlogs,
+		connect.WithSchema(blogServiceMethods.ByName("BatchGetBlogs")),
+		connect.WithHandlerOptions(opts...),
+	)
+	blogServiceBatchDeleteBlogsHandler := connect.NewUnaryHandler(
+		BlogServiceBatchDeleteBlogsProcedure,
+		svc.BatchDeleteBlogs,
+		connect.WithSchema(blogServiceMethods.ByName("BatchDeleteBlogs")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/blog.BlogService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case BlogServiceCreateBlogProcedure:
+			blogServiceCreateBlogHandler.ServeHTTP(w, r)
+		case BlogServiceReadBlogProcedure:
+			blogServiceReadBlogHandler.ServeHTTP(w, r)
+		case BlogServiceUpdateBlogProcedure:
+			blogServiceUpdateBlogHandler.ServeHTTP(w, r)
+		case BlogServiceDeleteBlogProcedure:
+			blogServiceDeleteBlogHandler.ServeHTTP(w, r)
+		case BlogServiceListBlogProcedure:
+			blogServiceListBlogHandler.ServeHTTP(w, r)
+		case BlogServiceDownloadImageProcedure:
+			blogServiceDownloadImageHandler.ServeHTTP(w, r)
+		case BlogServiceImportBlogsProcedure:
+			blogServiceImportBlogsHandler.ServeHTTP(w, r)
+		case BlogServiceExportBlogsProcedure:
+			blogServiceExportBlogsHandler.ServeHTTP(w, r)
+		case BlogServiceBatchCreateBlogsProcedure:
+			blogServiceBatchCreateBlogsHandler.ServeHTTP(w, r)
+		case BlogServiceBatchGetBlogsProcedure:
+			blogServiceBatchGetBlogsHandler.ServeHTTP(w, r)
+		case BlogServiceBatchDeleteBlogsProcedure:
+			blogServiceBatchDeleteBlogsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedBlogServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedBlogServiceHandler struct{}
+
+func (UnimplementedBlogServiceHandler) CreateBlog(context.Context, *connect.Request[blogpb.CreateBlogRequest]) (*connect.Response[blogpb.CreateBlogResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.CreateBlog is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) ReadBlog(context.Context, *connect.Request[blogpb.ReadBlogRequest]) (*connect.Response[blogpb.ReadBlogResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.ReadBlog is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) UpdateBlog(context.Context, *connect.Request[blogpb.UpdateBlogRequest]) (*connect.Response[blogpb.UpdateBlogResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.UpdateBlog is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) DeleteBlog(context.Context, *connect.Request[blogpb.DeleteBlogRequest]) (*connect.Response[blogpb.DeleteBlogResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.DeleteBlog is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) ListBlog(context.Context, *connect.Request[blogpb.ListBlogRequest], *connect.ServerStream[blogpb.ListBlogResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.ListBlog is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) DownloadImage(context.Context, *connect.Request[blogpb.DownloadImageRequest], *connect.ServerStream[blogpb.DownloadImageResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.DownloadImage is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) ImportBlogs(context.Context, *connect.ClientStream[blogpb.ImportBlogsRequest]) (*connect.Response[blogpb.ImportBlogsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.ImportBlogs is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) ExportBlogs(context.Context, *connect.Request[blogpb.ExportBlogsRequest], *connect.ServerStream[blogpb.ExportBlogsResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.ExportBlogs is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) BatchCreateBlogs(context.Context, *connect.Request[blogpb.BatchCreateBlogsRequest]) (*connect.Response[blogpb.BatchCreateBlogsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.BatchCreateBlogs is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) BatchGetBlogs(context.Context, *connect.Request[blogpb.BatchGetBlogsRequest]) (*connect.Response[blogpb.BatchGetBlogsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.BatchGetBlogs is not implemented"))
+}
+
+func (UnimplementedBlogServiceHandler) BatchDeleteBlogs(context.Context, *connect.Request[blogpb.BatchDeleteBlogsRequest]) (*connect.Response[blogpb.BatchDeleteBlogsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("blog.BlogService.BatchDeleteBlogs is not implemented"))
+}
@@ -0,0 +1,74 @@
+package blogpbconnect
+
+import (
+	"context"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"github.com/pjserol/tuto-grpc-go/httpmux"
+	"google.golang.org/grpc"
+)
+
+// NewBlogServiceProxyHandler returns the path and the Connect handler of
+// the BlogService, proxying the calls to the gRPC server of cc. It is a
+// httpmux.ConnectService.
+func NewBlogServiceProxyHandler(cc grpc.ClientConnInterface) (string, http.Handler) {
+	return NewBlogServiceHandler(&blogServiceProxy{client: blogpb.NewBlogServiceClient(cc)})
+}
+
+type blogServiceProxy struct {
+	client blogpb.BlogServiceClient
+}
+
+func (p *blogServiceProxy) CreateBlog(ctx context.Context, req *connect.Request[blogpb.CreateBlogRequest]) (*connect.Response[blogpb.CreateBlogResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.CreateBlog)
+}
+
+func (p *blogServiceProxy) ReadBlog(ctx context.Context, req *connect.Request[blogpb.ReadBlogRequest]) (*connect.Response[blogpb.ReadBlogResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.ReadBlog)
+}
+
+func (p *blogServiceProxy) UpdateBlog(ctx context.Context, req *connect.Request[blogpb.UpdateBlogRequest]) (*connect.Response[blogpb.UpdateBlogResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.UpdateBlog)
+}
+
+func (p *blogServiceProxy) DeleteBlog(ctx context.Context, req *connect.Request[blogpb.DeleteBlogRequest]) (*connect.Response[blogpb.DeleteBlogResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.DeleteBlog)
+}
+
+func (p *blogServiceProxy) ListBlog(ctx context.Context, req *connect.Request[blogpb.ListBlogRequest], stream *connect.ServerStream[blogpb.ListBlogResponse]) error {
+	return httpmux.ProxyServerStream(ctx, req, stream, func(ctx context.Context, req *blogpb.ListBlogRequest) (httpmux.Receiver[blogpb.ListBlogResponse], error) {
+		return p.client.ListBlog(ctx, req)
+	})
+}
+
+func (p *blogServiceProxy) DownloadImage(ctx context.Context, req *connect.Request[blogpb.DownloadImageRequest], stream *connect.ServerStream[blogpb.DownloadImageResponse]) error {
+	return httpmux.ProxyServerStream(ctx, req, stream, func(ctx context.Context, req *blogpb.DownloadImageRequest) (httpmux.Receiver[blogpb.DownloadImageResponse], error) {
+		return p.client.DownloadImage(ctx, req)
+	})
+}
+
+func (p *blogServiceProxy) ImportBlogs(ctx context.Context, stream *connect.ClientStream[blogpb.ImportBlogsRequest]) (*connect.Response[blogpb.ImportBlogsResponse], error) {
+	return httpmux.ProxyClientStream(ctx, stream, func(ctx context.Context) (httpmux.ClientStreamer[blogpb.ImportBlogsRequest, blogpb.ImportBlogsResponse], error) {
+		return p.client.ImportBlogs(ctx)
+	})
+}
+
+func (p *blogServiceProxy) ExportBlogs(ctx context.Context, req *connect.Request[blogpb.ExportBlogsRequest], stream *connect.ServerStream[blogpb.ExportBlogsResponse]) error {
+	return httpmux.ProxyServerStream(ctx, req, stream, func(ctx context.Context, req *blogpb.ExportBlogsRequest) (httpmux.Receiver[blogpb.ExportBlogsResponse], error) {
+		return p.client.ExportBlogs(ctx, req)
+	})
+}
+
+func (p *blogServiceProxy) BatchCreateBlogs(ctx context.Context, req *connect.Request[blogpb.BatchCreateBlogsRequest]) (*connect.Response[blogpb.BatchCreateBlogsResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.BatchCreateBlogs)
+}
+
+func (p *blogServiceProxy) BatchGetBlogs(ctx context.Context, req *connect.Request[blogpb.BatchGetBlogsRequest]) (*connect.Response[blogpb.BatchGetBlogsResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.BatchGetBlogs)
+}
+
+func (p *blogServiceProxy) BatchDeleteBlogs(ctx context.Context, req *connect.Request[blogpb.BatchDeleteBlogsRequest]) (*connect.Response[blogpb.BatchDeleteBlogsResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.BatchDeleteBlogs)
+}
@@ -0,0 +1,64 @@
+package blogpb
+
+import "github.com/pjserol/tuto-grpc-go/validation"
+
+// MaxBatchSize is the number of blogs of a batch at most.
+const MaxBatchSize = 100
+
+// objectIDPattern matches the hexadecimal representation of a MongoDB
+// ObjectID.
+const objectIDPattern = "^[0-9a-f]{24}$"
+
+// fileNamePattern matches the names of the files in the directory of the
+// server: without path separators nor two dots in a row, and other than
+// ".", so that they cannot escape it.
+const fileNamePattern = `^(\.?[^./\\])+\.?$`
+
+var blogRules = validation.Rules{
+	"blog":           {Required: true},
+	"blog.author_id": {Required: true, MaxLen: 100},
+	"blog.title":     {Required: true, MaxLen: 200},
+	"blog.content":   {MaxLen: 100000},
+}
+
+func init() {
+	validation.Register(&CreateBlogRequest{}, blogRules)
+	validation.Register(&ReadBlogRequest{}, validation.Rules{
+		"blog_id": {Required: true, Pattern: objectIDPattern},
+	})
+	validation.Register(&UpdateBlogRequest{}, withRules(blogRules, validation.Rules{
+		"blog.id": {Required: true, Pattern: objectIDPattern},
+	}))
+	validation.Register(&DeleteBlogRequest{}, validation.Rules{
+		"blog_id": {Required: true, Pattern: objectIDPattern},
+	})
+	validation.Register(&DownloadImageRequest{}, validation.Rules{
+		"fileName": {Required: true, MaxLen: 255, Pattern: fileNamePattern},
+	})
+	// the blogs of ImportBlogsRequest are checked one by one by the server,
+	// so that an invalid blog does not fail the stream
+	validation.Register(&ExportBlogsRequest{}, validation.Rules{
+		"author_id": {MaxLen: 100},
+	})
+	// the blogs of the batches are checked one by one by the server as
+	// well, to return the error of each blog
+	validation.Register(&BatchCreateBlogsRequest{}, validation.Rules{
+		"blogs": {Required: true, MaxLen: MaxBatchSize},
+	})
+	validation.Register(&BatchGetBlogsRequest{}, validation.Rules{
+		"blog_ids": {Required: true, MaxLen: MaxBatchSize},
+	})
+	validation.Register(&BatchDeleteBlogsRequest{}, validation.Rules{
+		"blog_ids": {Required: true, MaxLen: MaxBatchSize},
+	})
+}
+
+func withRules(rules ...validation.Rules) validation.Rules {
+	merged := validation.Rules{}
+	for _, r := range rules {
+		for path, rule := range r {
+			merged[path] = rule
+		}
+	}
+	return merged
+}
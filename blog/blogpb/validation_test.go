@@ -0,0 +1,36 @@
+package blogpb
+
+import (
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/validation"
+)
+
+func TestDownloadImageRequestFileName(t *testing.T) {
+	tests := []struct {
+		fileName string
+		valid    bool
+	}{
+		{"image.png", true},
+		{".hidden", true},
+		{"archive.tar.gz", true},
+		{"trailing.", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"../../etc/passwd", false},
+		{"/etc/passwd", false},
+		{"images/image.png", false},
+		{`..\windows\win.ini`, false},
+		{"image..png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			err := validation.Validate(&DownloadImageRequest{FileName: tt.fileName})
+			if (err == nil) != tt.valid {
+				t.Errorf("Validate(%q) = %v, want valid %v", tt.fileName, err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package blogserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"github.com/pjserol/tuto-grpc-go/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// BatchCreateBlogs creates the blogs, checked with the rules of CreateBlog,
+// with new ids.
+func (s *Server) BatchCreateBlogs(ctx context.Context, req *blogpb.BatchCreateBlogsRequest) (*blogpb.BatchCreateBlogsResponse, error) {
+	blogs := req.GetBlogs()
+
+	results, err := batch(ctx, s.inTransaction, "blogs", make([]string, len(blogs)), req.GetAtomic(), func(ctx context.Context, i int) (*blogpb.Blog, error) {
+		blog := blogs[i]
+		if err := validation.Validate(&blogpb.CreateBlogRequest{Blog: blog}); err != nil {
+			return nil, err
+		}
+
+		data, err := s.insertBlog(ctx, blogItem{
+			AuthorID: blog.GetAuthorId(),
+			Title:    blog.GetTitle(),
+			Content:  blog.GetContent(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dataToBlogPb(&data), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &blogpb.BatchCreateBlogsResponse{Results: results}, nil
+}
+
+// BatchGetBlogs reads the blogs in a single query, the blogs not found
+// failing with NOT_FOUND.
+func (s *Server) BatchGetBlogs(ctx context.Context, req *blogpb.BatchGetBlogsRequest) (*blogpb.BatchGetBlogsResponse, error) {
+	ids := req.GetBlogIds()
+	results := make([]*blogpb.BlogResult, len(ids))
+
+	blogIDs := make([]primitive.ObjectID, 0, len(ids))
+	for i, id := range ids {
+		blogID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			results[i] = errorResult(id, batchError("blog_ids", i, grpcerr.InvalidArgument("blog_id", "Cannot parse ID")))
+			continue
+		}
+		blogIDs = append(blogIDs, blogID)
+	}
+
+	blogs, err := s.findBlogsByIDs(ctx, blogIDs)
+	if err != nil {
+		return nil, statusError(ctx, err, "")
+	}
+
+	for i, id := range ids {
+		if results[i] != nil {
+			continue
+		}
+		// the id parsed above
+		blogID, _ := primitive.ObjectIDFromHex(id)
+		if data, ok := blogs[blogID]; ok {
+			results[i] = &blogpb.BlogResult{Blog: dataToBlogPb(&data), BlogId: id}
+		} else {
+			results[i] = errorResult(id, batchError("blog_ids", i, grpcerr.NotFound(resourceType, id)))
+		}
+	}
+
+	return &blogpb.BatchGetBlogsResponse{Results: results}, nil
+}
+
+// BatchDeleteBlogs deletes the blogs, the blogs not found failing with
+// NOT_FOUND.
+func (s *Server) BatchDeleteBlogs(ctx context.Context, req *blogpb.BatchDeleteBlogsRequest) (*blogpb.BatchDeleteBlogsResponse, error) {
+	ids := req.GetBlogIds()
+
+	results, err := batch(ctx, s.inTransaction, "blog_ids", ids, req.GetAtomic(), func(ctx context.Context, i int) (*blogpb.Blog, error) {
+		blogID, err := primitive.ObjectIDFromHex(ids[i])
+		if err != nil {
+			return nil, grpcerr.InvalidArgument("blog_id", "Cannot parse ID")
+		}
+		return nil, s.deleteBlog(ctx, blogID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &blogpb.BatchDeleteBlogsResponse{Results: results}, nil
+}
+
+// batch runs item for the blog of each id of a batch, an empty id for the
+// blogs created, and returns their results. The blog returned by item is
+// set in the result, and its id replaces the one given.
+//
+// When atomic, the items are run by inTransaction, aborted by the first
+// error, which is then the error of the batch. Otherwise only the
+// unavailability of the database or the end of the call fail the batch.
+func batch(ctx context.Context, inTransaction func(ctx context.Context, fn func(ctx context.Context) error) error, field string, ids []string, atomic bool, item func(ctx context.Context, i int) (*blogpb.Blog, error)) ([]*blogpb.BlogResult, error) {
+	results := make([]*blogpb.BlogResult, len(ids))
+
+	// failed is the index of the blog failing an atomic batch
+	failed := -1
+	run := func(ctx context.Context) error {
+		// the transaction runs again on the transient errors
+		failed = -1
+		for i, id := range ids {
+			blog, err := item(ctx, i)
+			switch {
+			case err != nil && ctx.Err() != nil:
+				// the client canceled the call, or its deadline is exceeded
+				return status.FromContextError(ctx.Err()).Err()
+			case errors.Is(err, errUnavailable), errors.Is(err, errNoTransactions):
+				return err
+			case err != nil && atomic:
+				failed = i
+				return err
+			case err != nil:
+				results[i] = errorResult(id, batchError(field, i, itemError(ctx, err, id)))
+			case blog != nil:
+				results[i] = &blogpb.BlogResult{Blog: blog, BlogId: blog.GetId()}
+			default:
+				results[i] = &blogpb.BlogResult{BlogId: id}
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if atomic {
+		err = inTransaction(ctx, run)
+	} else {
+		err = run(ctx)
+	}
+
+	switch {
+	case err == nil:
+		return results, nil
+	case failed >= 0:
+		return nil, batchError(field, failed, itemError(ctx, err, ids[failed]))
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+	return nil, statusError(ctx, err, "")
+}
+
+func errorResult(blogID string, err error) *blogpb.BlogResult {
+	return &blogpb.BlogResult{BlogId: blogID, Error: status.Convert(err).Proto()}
+}
+
+// batchError returns err, the error of the item at index i of the field of
+// a batch, with the field and index prefixed to its message, and replacing
+// the request of the item in the paths of its fields: "blog.title" becomes
+// "blogs[1].title".
+func batchError(field string, i int, err error) error {
+	prefix := fmt.Sprintf("%s[%d]", field, i)
+	st := status.Convert(err).Proto()
+	st.Message = prefix + ": " + st.GetMessage()
+
+	for _, d := range st.GetDetails() {
+		info := &errdetails.ErrorInfo{}
+		if d.MessageIs(info) && d.UnmarshalTo(info) == nil {
+			if path, ok := info.GetMetadata()["field"]; ok {
+				info.Metadata["field"] = itemPath(prefix, path)
+				_ = d.MarshalFrom(info)
+			}
+			continue
+		}
+		req := &errdetails.BadRequest{}
+		if d.MessageIs(req) && d.UnmarshalTo(req) == nil {
+			for _, v := range req.GetFieldViolations() {
+				v.Field = itemPath(prefix, v.GetField())
+			}
+			_ = d.MarshalFrom(req)
+		}
+	}
+	return status.FromProto(st).Err()
+}
+
+// itemPath returns the path of a field of the request of a single item,
+// whose first name is the item, in the batch at prefix.
+func itemPath(prefix string, path string) string {
+	if _, rest, ok := strings.Cut(path, "."); ok {
+		return prefix + "." + rest
+	}
+	return prefix
+}
@@ -0,0 +1,244 @@
+package blogserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"github.com/pjserol/tuto-grpc-go/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBatchRequestsValidation(t *testing.T) {
+	blogs := func(n int) []*blogpb.Blog {
+		b := make([]*blogpb.Blog, n)
+		for i := range b {
+			b[i] = &blogpb.Blog{AuthorId: "author", Title: "title"}
+		}
+		return b
+	}
+	ids := func(n int) []string {
+		return make([]string, n)
+	}
+
+	tests := []struct {
+		name string
+		req  proto.Message
+		// wantField is the field of the violation, empty for a valid request
+		wantField string
+	}{
+		{name: "create", req: &blogpb.BatchCreateBlogsRequest{Blogs: blogs(blogpb.MaxBatchSize)}},
+		{name: "create too many", req: &blogpb.BatchCreateBlogsRequest{Blogs: blogs(blogpb.MaxBatchSize + 1)}, wantField: "blogs"},
+		{name: "create nothing", req: &blogpb.BatchCreateBlogsRequest{Atomic: true}, wantField: "blogs"},
+		{name: "get", req: &blogpb.BatchGetBlogsRequest{BlogIds: ids(blogpb.MaxBatchSize)}},
+		{name: "get too many", req: &blogpb.BatchGetBlogsRequest{BlogIds: ids(blogpb.MaxBatchSize + 1)}, wantField: "blog_ids"},
+		{name: "get nothing", req: &blogpb.BatchGetBlogsRequest{}, wantField: "blog_ids"},
+		{name: "delete", req: &blogpb.BatchDeleteBlogsRequest{BlogIds: ids(1)}},
+		{name: "delete too many", req: &blogpb.BatchDeleteBlogsRequest{BlogIds: ids(blogpb.MaxBatchSize + 1)}, wantField: "blog_ids"},
+		{name: "delete nothing", req: &blogpb.BatchDeleteBlogsRequest{Atomic: true}, wantField: "blog_ids"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.Validate(tt.req)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v", err)
+				}
+				return
+			}
+			if status.Code(err) != codes.InvalidArgument {
+				t.Fatalf("Validate() = %v, want InvalidArgument", err)
+			}
+			checkFields(t, err, tt.wantField)
+		})
+	}
+}
+
+func TestBatchItemErrors(t *testing.T) {
+	s := &Server{}
+	ctx := context.Background()
+
+	// the blogs are invalid, so that the database is not called
+	created, err := s.BatchCreateBlogs(ctx, &blogpb.BatchCreateBlogsRequest{Blogs: []*blogpb.Blog{
+		{AuthorId: "author"},
+		{Title: "title"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, created.GetResults(), "blogs[0].title", "blogs[1].author_id")
+
+	deleted, err := s.BatchDeleteBlogs(ctx, &blogpb.BatchDeleteBlogsRequest{BlogIds: []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, deleted.GetResults(), "blog_ids[0]", "blog_ids[1]")
+	if id := deleted.GetResults()[1].GetBlogId(); id != "b" {
+		t.Errorf("blog id of the result = %q, want b", id)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	// noTransactions is the error of a transaction on a standalone server
+	noTransactions := storageError(mongo.CommandError{
+		Code:    illegalOperation,
+		Message: "Transaction numbers are only allowed on a replica set member or mongos",
+	}, primitive.NilObjectID)
+	invalid := grpcerr.InvalidArgument("blog.title", "must be set")
+
+	tests := []struct {
+		name   string
+		atomic bool
+		// transaction is the error of the transaction, before running the
+		// items
+		transaction error
+		// errs are the errors of the items
+		errs []error
+		// canceled cancels the call before the items
+		canceled bool
+		wantCode codes.Code
+		// wantField is the field of the error of the batch
+		wantField string
+		// wantResults are the codes of the results of the items
+		wantResults []codes.Code
+	}{
+		{
+			name:        "items failed",
+			errs:        []error{nil, invalid, errNotFound},
+			wantResults: []codes.Code{codes.OK, codes.InvalidArgument, codes.NotFound},
+		},
+		{
+			name:      "atomic item failed",
+			atomic:    true,
+			errs:      []error{nil, invalid, nil},
+			wantCode:  codes.InvalidArgument,
+			wantField: "blogs[1].title",
+		},
+		{
+			name:     "atomic storage error",
+			atomic:   true,
+			errs:     []error{errAlreadyExists},
+			wantCode: codes.AlreadyExists,
+		},
+		{
+			name:        "atomic",
+			atomic:      true,
+			errs:        []error{nil, nil},
+			wantResults: []codes.Code{codes.OK, codes.OK},
+		},
+		{
+			name:        "transactions unavailable",
+			atomic:      true,
+			transaction: noTransactions,
+			errs:        []error{nil},
+			wantCode:    codes.FailedPrecondition,
+		},
+		{
+			name:     "transactions unavailable on the first write",
+			atomic:   true,
+			errs:     []error{noTransactions, nil},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:     "database unavailable",
+			errs:     []error{nil, errUnavailable},
+			wantCode: codes.Unavailable,
+		},
+		{
+			name:     "canceled",
+			errs:     []error{errors.New("context canceled")},
+			canceled: true,
+			wantCode: codes.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.canceled {
+				cancel()
+			}
+
+			transactions := 0
+			inTransaction := func(ctx context.Context, fn func(ctx context.Context) error) error {
+				transactions++
+				if tt.transaction != nil {
+					return tt.transaction
+				}
+				return fn(ctx)
+			}
+			item := func(ctx context.Context, i int) (*blogpb.Blog, error) {
+				return nil, tt.errs[i]
+			}
+
+			results, err := batch(ctx, inTransaction, "blogs", make([]string, len(tt.errs)), tt.atomic, item)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("batch() = %v, want code %v", err, tt.wantCode)
+			}
+			if (transactions == 1) != tt.atomic {
+				t.Errorf("%d transactions, atomic %v", transactions, tt.atomic)
+			}
+			if tt.wantField != "" {
+				checkFields(t, err, tt.wantField)
+				if !strings.HasPrefix(status.Convert(err).Message(), "blogs[1]: ") {
+					t.Errorf("message = %q, want the item prefixed", status.Convert(err).Message())
+				}
+			}
+			if tt.wantCode == codes.FailedPrecondition {
+				if failure, ok := grpcerr.PreconditionFailure(err); !ok || failure.GetViolations()[0].GetType() != "CONFIG" {
+					t.Errorf("PreconditionFailure = %v, want a CONFIG violation", failure)
+				}
+			}
+
+			if len(results) != len(tt.wantResults) {
+				t.Fatalf("%d results, want %d", len(results), len(tt.wantResults))
+			}
+			for i, r := range results {
+				if got := codes.Code(r.GetError().GetCode()); got != tt.wantResults[i] {
+					t.Errorf("result %d: code %v, want %v", i, got, tt.wantResults[i])
+				}
+			}
+		})
+	}
+}
+
+// checkResults checks that each result failed with InvalidArgument for the
+// field.
+func checkResults(t *testing.T, results []*blogpb.BlogResult, fields ...string) {
+	t.Helper()
+
+	if len(results) != len(fields) {
+		t.Fatalf("%d results, want %d", len(results), len(fields))
+	}
+	for i, r := range results {
+		err := status.ErrorProto(r.GetError())
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("result %d: error = %v, want InvalidArgument", i, err)
+			continue
+		}
+		checkFields(t, err, fields[i])
+	}
+}
+
+// checkFields checks that field is the field of the ErrorInfo and of the
+// BadRequest violation of err.
+func checkFields(t *testing.T, err error, field string) {
+	t.Helper()
+
+	if info, ok := grpcerr.ErrorInfo(err); !ok || info.GetMetadata()["field"] != field {
+		t.Errorf("ErrorInfo = %v, want the field %s", info, field)
+	}
+	req, ok := grpcerr.BadRequest(err)
+	if !ok || len(req.GetFieldViolations()) != 1 || req.GetFieldViolations()[0].GetField() != field {
+		t.Errorf("BadRequest = %v, want the field %s", req, field)
+	}
+}
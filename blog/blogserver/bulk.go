@@ -0,0 +1,130 @@
+package blogserver
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"github.com/pjserol/tuto-grpc-go/validation"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/status"
+)
+
+// maxImportErrors is the number of errors returned by ImportBlogs, the
+// others being only counted.
+const maxImportErrors = 1000
+
+// ImportBlogs creates or replaces the blogs one by one. The errors of the
+// blogs are returned with their index, and only the unavailability of the
+// database or the end of the call stop the import.
+func (s *Server) ImportBlogs(stream blogpb.BlogService_ImportBlogsServer) error {
+	ctx := stream.Context()
+	res := &blogpb.ImportBlogsResponse{}
+
+	for index := int32(0); ; index++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(res)
+		} else if err != nil {
+			return err
+		}
+		if index == 0 {
+			res.DryRun = req.GetDryRun()
+		}
+
+		created, err := s.importBlog(ctx, req.GetBlog(), res.DryRun)
+		if err != nil && ctx.Err() != nil {
+			// the client canceled the call, or its deadline is exceeded
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		switch {
+		case errors.Is(err, errUnavailable):
+			return statusError(ctx, err, req.GetBlog().GetId())
+		case err != nil:
+			res.Failed++
+			if len(res.Errors) < maxImportErrors {
+				res.Errors = append(res.Errors, &blogpb.ImportError{
+					Index:  index,
+					BlogId: req.GetBlog().GetId(),
+					Error:  status.Convert(itemError(ctx, err, req.GetBlog().GetId())).Proto(),
+				})
+			}
+		case created:
+			res.Created++
+		default:
+			res.Updated++
+		}
+	}
+}
+
+// importBlog creates or replaces blog, checked with the rules of
+// CreateBlog, and reports whether it was created. In a dry run, it only
+// checks whether the blog exists.
+func (s *Server) importBlog(ctx context.Context, blog *blogpb.Blog, dryRun bool) (bool, error) {
+	if err := validation.Validate(&blogpb.CreateBlogRequest{Blog: blog}); err != nil {
+		return false, err
+	}
+
+	data := blogItem{
+		AuthorID: blog.GetAuthorId(),
+		Title:    blog.GetTitle(),
+		Content:  blog.GetContent(),
+	}
+	if blog.GetId() == "" {
+		if dryRun {
+			return true, nil
+		}
+		_, err := s.insertBlog(ctx, data)
+		return err == nil, err
+	}
+
+	blogID, err := primitive.ObjectIDFromHex(blog.GetId())
+	if err != nil {
+		return false, grpcerr.InvalidArgument("blog.id", "Cannot parse ID")
+	}
+	if dryRun {
+		exists, err := s.blogExists(ctx, blogID)
+		return !exists, err
+	}
+	return s.upsertBlog(ctx, blogID, data)
+}
+
+// itemError converts the error of a blog of ImportBlogs or of a batch to
+// the error returned for the blog.
+func itemError(ctx context.Context, err error, blogID string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return statusError(ctx, err, blogID)
+}
+
+// ExportBlogs streams the blogs in the order of their ids, so that an
+// export can be compared with another one.
+func (s *Server) ExportBlogs(req *blogpb.ExportBlogsRequest, stream blogpb.BlogService_ExportBlogsServer) error {
+	ctx := stream.Context()
+
+	cur, err := s.exportBlogs(ctx, req.GetAuthorId())
+	if err != nil {
+		return statusError(ctx, err, "")
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		data := &blogItem{}
+		if err := cur.Decode(data); err != nil {
+			return statusError(ctx, err, "")
+		}
+		if err := stream.Send(&blogpb.ExportBlogsResponse{Blog: dataToBlogPb(data)}); err != nil {
+			return err
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		return statusError(ctx, storageError(err, primitive.NilObjectID), "")
+	}
+
+	return nil
+}
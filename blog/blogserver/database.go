@@ -0,0 +1,213 @@
+package blogserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Errors returned by the storage functions, wrapped with the ID of the blog
+// when there is one. Use errors.Is to check them.
+var (
+	errNotFound      = errors.New("blog not found")
+	errAlreadyExists = errors.New("blog already exists")
+	// errConflict means that the write conflicted with another one and can
+	// be retried.
+	errConflict = errors.New("conflicting write")
+	// errUnavailable means that the database cannot be reached.
+	errUnavailable = errors.New("database unavailable")
+	// errNoTransactions means that the database is a standalone server,
+	// transactions needing a replica set.
+	errNoTransactions = errors.New("transactions not supported")
+)
+
+// illegalOperation is the code of the error of a transaction on a
+// standalone server.
+const illegalOperation = 20
+
+// storageError converts an error of the MongoDB driver to one of the errors
+// above when possible.
+func storageError(err error, id primitive.ObjectID) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return fmt.Errorf("%w: %s", errNotFound, id.Hex())
+	case mongo.IsDuplicateKeyError(err):
+		return fmt.Errorf("%w: %s", errAlreadyExists, id.Hex())
+	case mongo.IsNetworkError(err), mongo.IsTimeout(err):
+		return fmt.Errorf("%w: %v", errUnavailable, err)
+	}
+
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == illegalOperation && strings.Contains(cmdErr.Message, "Transaction numbers") {
+		return fmt.Errorf("%w: %v", errNoTransactions, err)
+	}
+
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) && labeled.HasErrorLabel("TransientTransactionError") {
+		return fmt.Errorf("%w: %v", errConflict, err)
+	}
+	return err
+}
+
+func (s *Server) insertBlog(ctx context.Context, item blogItem) (blogItem, error) {
+	res, err := s.collection.InsertOne(ctx, item)
+	if err != nil {
+		return blogItem{}, storageError(err, item.ID)
+	}
+
+	var ok bool
+	item.ID, ok = res.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return blogItem{}, errors.New("Cannot convert ObjectID")
+	}
+
+	return item, nil
+}
+
+func (s *Server) findBlogByID(ctx context.Context, id primitive.ObjectID) (blogItem, error) {
+	data := &blogItem{}
+	filter := bson.M{"_id": id}
+
+	res := s.collection.FindOne(ctx, filter)
+
+	if err := res.Decode(data); err != nil {
+		return blogItem{}, storageError(err, id)
+	}
+
+	return *data, nil
+}
+
+func (s *Server) updateBlog(ctx context.Context, id primitive.ObjectID, item blogItem) (blogItem, error) {
+	filter := bson.M{"_id": id}
+
+	// use UpdateOne to update only the field that we want
+	res, err := s.collection.ReplaceOne(ctx, filter, item)
+	if err != nil {
+		return blogItem{}, storageError(err, id)
+	}
+
+	// ModifiedCount is 0 as well when the blog is unchanged
+	if res.MatchedCount == 0 {
+		return blogItem{}, fmt.Errorf("%w: %s", errNotFound, id.Hex())
+	}
+
+	item.ID = id
+	return item, nil
+}
+
+func (s *Server) deleteBlog(ctx context.Context, id primitive.ObjectID) error {
+	filter := bson.M{"_id": id}
+
+	res, err := s.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return storageError(err, id)
+	}
+
+	if res.DeletedCount == 0 {
+		return fmt.Errorf("%w: %s", errNotFound, id.Hex())
+	}
+
+	return nil
+}
+
+func (s *Server) listBlog(ctx context.Context) (*mongo.Cursor, error) {
+	cur, err := s.collection.Find(ctx, primitive.D{{}})
+	if err != nil {
+		return nil, storageError(err, primitive.NilObjectID)
+	}
+
+	return cur, nil
+}
+
+// upsertBlog replaces the blog of id, or creates it with id, and reports
+// whether it was created.
+func (s *Server) upsertBlog(ctx context.Context, id primitive.ObjectID, item blogItem) (bool, error) {
+	filter := bson.M{"_id": id}
+
+	item.ID = id
+	res, err := s.collection.ReplaceOne(ctx, filter, item, options.Replace().SetUpsert(true))
+	if err != nil {
+		return false, storageError(err, id)
+	}
+
+	return res.UpsertedCount > 0, nil
+}
+
+func (s *Server) blogExists(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	filter := bson.M{"_id": id}
+
+	n, err := s.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, storageError(err, id)
+	}
+
+	return n > 0, nil
+}
+
+// exportBlogs returns the blogs of authorID, or all of them when it is
+// empty, in the order of their ids.
+func (s *Server) exportBlogs(ctx context.Context, authorID string) (*mongo.Cursor, error) {
+	filter := bson.M{}
+	if authorID != "" {
+		filter["author_id"] = authorID
+	}
+
+	cur, err := s.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, storageError(err, primitive.NilObjectID)
+	}
+
+	return cur, nil
+}
+
+// findBlogsByIDs returns the blogs of ids found, by id.
+func (s *Server) findBlogsByIDs(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]blogItem, error) {
+	filter := bson.M{"_id": bson.M{"$in": ids}}
+
+	cur, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, storageError(err, primitive.NilObjectID)
+	}
+
+	defer cur.Close(ctx)
+
+	blogs := make(map[primitive.ObjectID]blogItem, len(ids))
+	for cur.Next(ctx) {
+		data := blogItem{}
+		if err := cur.Decode(&data); err != nil {
+			return nil, err
+		}
+		blogs[data.ID] = data
+	}
+
+	if err := cur.Err(); err != nil {
+		return nil, storageError(err, primitive.NilObjectID)
+	}
+
+	return blogs, nil
+}
+
+// inTransaction runs fn in a transaction, committed when fn returns nil.
+// The functions above called by fn with its context are part of the
+// transaction.
+func (s *Server) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := s.collection.Database().Client().StartSession()
+	if err != nil {
+		return storageError(err, primitive.NilObjectID)
+	}
+
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return storageError(err, primitive.NilObjectID)
+}
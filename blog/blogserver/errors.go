@@ -0,0 +1,39 @@
+package blogserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"github.com/pjserol/tuto-grpc-go/interceptor"
+)
+
+// resourceType is the type of the resource in the error details.
+const resourceType = "blog"
+
+// statusError converts an error of the storage functions to the error
+// returned to the client. The text of the unexpected errors is logged but
+// not sent.
+func statusError(ctx context.Context, err error, blogID string) error {
+	switch {
+	case errors.Is(err, errNotFound):
+		return grpcerr.NotFound(resourceType, blogID)
+	case errors.Is(err, errAlreadyExists):
+		return grpcerr.AlreadyExists(resourceType, blogID)
+	case errors.Is(err, errConflict):
+		return grpcerr.Aborted("The blog was modified concurrently", time.Second)
+	case errors.Is(err, errUnavailable):
+		return grpcerr.Unavailable("The database is unavailable", 5*time.Second)
+	case errors.Is(err, errNoTransactions):
+		return grpcerr.FailedPrecondition("CONFIG", "database", "Transactions need a MongoDB replica set")
+	}
+
+	slog.ErrorContext(ctx, "storage error",
+		slog.String("request_id", interceptor.RequestIDFromContext(ctx)),
+		slog.String("blog_id", blogID),
+		slog.String("error", err.Error()),
+	)
+	return grpcerr.Internal("Internal error")
+}
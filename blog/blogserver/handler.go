@@ -0,0 +1,144 @@
+package blogserver
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *Server) CreateBlog(ctx context.Context, req *blogpb.CreateBlogRequest) (*blogpb.CreateBlogResponse, error) {
+	blog := req.GetBlog()
+
+	data := blogItem{
+		AuthorID: blog.GetAuthorId(),
+		Title:    blog.GetTitle(),
+		Content:  blog.GetContent(),
+	}
+
+	var err error
+	data, err = s.insertBlog(ctx, data)
+	if err != nil {
+		return nil, statusError(ctx, err, data.ID.Hex())
+	}
+
+	return &blogpb.CreateBlogResponse{
+		Blog: dataToBlogPb(&data),
+	}, nil
+}
+
+func (s *Server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
+	blogID, err := primitive.ObjectIDFromHex(req.GetBlogId())
+	if err != nil {
+		return nil, grpcerr.InvalidArgument("blog_id", "Cannot parse ID")
+	}
+
+	data, err := s.findBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, statusError(ctx, err, req.GetBlogId())
+	}
+
+	return &blogpb.ReadBlogResponse{
+		Blog: dataToBlogPb(&data),
+	}, nil
+}
+
+func (s *Server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (*blogpb.UpdateBlogResponse, error) {
+	blog := req.GetBlog()
+	blogID, err := primitive.ObjectIDFromHex(blog.GetId())
+	if err != nil {
+		return nil, grpcerr.InvalidArgument("blog.id", "Cannot parse ID")
+	}
+
+	data := blogItem{}
+	data.AuthorID = blog.GetAuthorId()
+	data.Content = blog.GetContent()
+	data.Title = blog.GetTitle()
+
+	item, err := s.updateBlog(ctx, blogID, data)
+	if err != nil {
+		return nil, statusError(ctx, err, blog.GetId())
+	}
+
+	return &blogpb.UpdateBlogResponse{
+		Blog: dataToBlogPb(&item),
+	}, nil
+
+}
+
+func (s *Server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (*blogpb.DeleteBlogResponse, error) {
+	blogID, err := primitive.ObjectIDFromHex(req.GetBlogId())
+	if err != nil {
+		return nil, grpcerr.InvalidArgument("blog_id", "Cannot parse ID")
+	}
+
+	if err := s.deleteBlog(ctx, blogID); err != nil {
+		return nil, statusError(ctx, err, req.GetBlogId())
+	}
+
+	return &blogpb.DeleteBlogResponse{BlogId: req.GetBlogId()}, nil
+}
+
+func (s *Server) ListBlog(req *blogpb.ListBlogRequest, stream blogpb.BlogService_ListBlogServer) error {
+	ctx := stream.Context()
+
+	cur, err := s.listBlog(ctx)
+	if err != nil {
+		return statusError(ctx, err, "")
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		data := &blogItem{}
+		err := cur.Decode(data)
+		if err != nil {
+			return statusError(ctx, err, "")
+		}
+		if err := stream.Send(&blogpb.ListBlogResponse{Blog: dataToBlogPb(data)}); err != nil {
+			return err
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		return statusError(ctx, storageError(err, primitive.NilObjectID), "")
+	}
+
+	return nil
+}
+
+func (s *Server) DownloadImage(req *blogpb.DownloadImageRequest, stream blogpb.BlogService_DownloadImageServer) error {
+	bufferSize := 64 * 1024 //64KiB, tweak this as desired
+
+	file, err := os.Open(req.GetFileName())
+	if os.IsNotExist(err) {
+		return grpcerr.NotFound("file", req.GetFileName())
+	} else if err != nil {
+		return statusError(stream.Context(), err, "")
+	}
+
+	defer file.Close()
+
+	buff := make([]byte, bufferSize)
+	for {
+		bytesRead, err := file.Read(buff)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return statusError(stream.Context(), err, "")
+			}
+		}
+
+		resp := &blogpb.DownloadImageResponse{
+			FileChunk: buff[:bytesRead],
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
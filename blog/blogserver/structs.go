@@ -1,4 +1,4 @@
-package main
+package blogserver
 
 import (
 	"github.com/pjserol/tuto-grpc-go/blog/blogpb"
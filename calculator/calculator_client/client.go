@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math/big"
 	"time"
 
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorclient"
 	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 func main() {
@@ -22,160 +26,436 @@ func main() {
 	}
 	defer cc.Close()
 
+	// client wraps the RPCs of the examples below with retries and typed
+	// errors, c calls the others
+	client := calculatorclient.New(cc, calculatorclient.Options{})
 	c := calculatorpb.NewCalculatorServiceClient(cc)
+	_ = c
 
-	doUnary(c)
+	doUnary(client)
 
-	//doServerStreaming(c)
+	//doServerStreaming(client)
 
-	//doClientStreaming(c)
+	//doClientStreaming(client)
 
-	//doBiDiStreaming(c)
+	//doBiDiStreaming(client)
 
 	// call with a success response
-	//doSquareRoot(c, 16)
+	//doSquareRoot(client, 16)
 
 	// call with an error
-	//doSquareRoot(c, -5)
+	//doSquareRoot(client, -5)
+
+	// arbitrary-precision arithmetic
+	//doBigCalculate(c, calculatorpb.BigOperation_POW, "2", "200")
+	//doBigCalculate(c, calculatorpb.BigOperation_SQRT, "2", "")
+
+	// expression evaluation
+	//doEvaluate(c, "2 * (x + 1) - max(y, 3) ^ 2", map[string]float64{"x": 2, "y": 5})
+	//doEvaluate(c, "2 * (x + ", nil)
+
+	// statistics
+	//doComputeStatistics(c)
+	//doRunningStatistics(c, 3)
+
+	// windowed aggregation
+	//doAggregate(c, &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_MAX, WindowSize: 3, Slide: 1})
+	//doAggregate(c, &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_AVG, WindowSize: 1000, Unit: calculatorpb.WindowUnit_MILLISECONDS})
+
+	// matrices
+	//doComputeMatrix(c, calculatorpb.MatrixOperation_MATRIX_SOLVE, [][]float64{{2, 1, -1}, {-3, -1, 2}, {-2, 1, 2}}, [][]float64{{8}, {-11}, {-3}})
+	//doComputeMatrix(c, calculatorpb.MatrixOperation_MATRIX_DETERMINANT, [][]float64{{1, 2}, {3, 4}}, nil)
+
+	// conversions
+	//doConvert(c, 100, "C", "F")
+	//doConvert(c, 1.5, "GiB", "MB")
+	//doConvert(c, 20, "EUR", "USD")
+
+	// async jobs
+	//doSubmitJob(c, &calculatorpb.SubmitJobRequest{Job: &calculatorpb.SubmitJobRequest_PrimeNumberDecomposition{PrimeNumberDecomposition: &calculatorpb.PrimeNumberDecompositionRequest{BigNumber: "1234567890123456789012345678901"}}})
 
 }
 
-func doUnary(c calculatorpb.CalculatorServiceClient) {
+func doUnary(c *calculatorclient.Client) {
 	log.Println("Starting to do a Unary RPC")
-	req := &calculatorpb.SumRequest{
-		FirstNumber:  10,
-		SecondNumber: 25,
-	}
 
-	res, err := c.Sum(context.Background(), req)
+	sum, err := c.Sum(context.Background(), 10, 25)
 	if err != nil {
-		log.Fatalf("error while calling Sum RPC: %v", err)
-
+		logError("Sum", err)
+		return
 	}
 
-	log.Printf("Response from Sum: %d", res.SumResult)
+	log.Printf("Response from Sum: %d", sum)
 }
 
-func doServerStreaming(c calculatorpb.CalculatorServiceClient) {
+func doServerStreaming(c *calculatorclient.Client) {
 	log.Println("Starting to do a Server Streaming RPC")
 
-	req := &calculatorpb.PrimeNumberDecompositionRequest{
-		Number: 125,
+	factors := c.Factorize(context.Background(), big.NewInt(125))
+	for factors.Next() {
+		f := factors.Factor()
+		fmt.Printf("%s^%d ", f.Prime, f.Multiplicity)
+	}
+	if err := factors.Err(); err != nil {
+		logError("PrimeNumberDecomposition", err)
 	}
+}
+
+func doClientStreaming(c *calculatorclient.Client) {
+	log.Println("Starting to do a Client Streaming")
+
+	numbers := []int32{12, 4, 23, 13, 20}
 
-	resStream, err := c.PrimeNumberDecomposition(context.Background(), req)
+	average, err := c.Average(context.Background(), numbers)
 	if err != nil {
-		log.Fatalf("error while calling PrimeNumberDecomposition RPC: %v", err)
+		logError("ComputeAverage", err)
+		return
 	}
 
-	for {
-		msg, err := resStream.Recv()
-		if err == io.EOF {
-			// we've reached the end of the stream
-			break
-		} else if err != nil {
-			log.Fatalf("error while reading stream: %v", err)
+	log.Printf("Response from Compute: %v", average)
+}
+
+func doBiDiStreaming(c *calculatorclient.Client) {
+	log.Println("Starting to do a BiDi Streaming")
+
+	numbers := make(chan int32)
+
+	// Send number to the server
+	go func() {
+		for _, number := range []int32{3, 7, 2, 15, 22, 10, 8, 24, 1} {
+			fmt.Printf("Sending maximum: %d\n", number)
+			numbers <- number
+			time.Sleep(1000 * time.Millisecond)
 		}
+		close(numbers)
+	}()
 
-		fmt.Printf("%d ", msg.GetPrimeFactor())
+	// Receive max from server
+	maximums := c.Max(context.Background(), numbers)
+	for maximums.Next() {
+		fmt.Printf("Received maximum: %d\n", maximums.Maximum())
+	}
+	if err := maximums.Err(); err != nil {
+		logError("FindMaximum", err)
 	}
 }
 
-func doClientStreaming(c calculatorpb.CalculatorServiceClient) {
-	log.Println("Starting to do a Client Streaming")
+func doSquareRoot(c *calculatorclient.Client, n int32) {
+	log.Println("Starting to do a Unary - SquareRoot")
+
+	root, err := c.SquareRoot(context.Background(), n)
+	if errors.Is(err, calculatorclient.ErrInvalidArgument) {
+		log.Println("Negative number not allowed!")
+		logError("SquareRoot", err)
+	} else if err != nil {
+		logError("SquareRoot", err)
+	} else {
+		fmt.Printf("SUCCESS::\nResponse from SquareRoot: %v\n", root)
+	}
+}
 
-	stream, err := c.ComputeAverage(context.Background())
+// logError logs err with the details sent by the server. err is an
+// *calculatorclient.Error, or an error of the generated client.
+func logError(call string, err error) {
+	log.Printf("error while calling %s: %v", call, err)
+
+	if req, ok := grpcerr.BadRequest(err); ok {
+		for _, v := range req.GetFieldViolations() {
+			log.Printf("Field %s: %s", v.GetField(), v.GetDescription())
+		}
+	}
+	if delay, ok := grpcerr.RetryDelay(err); ok {
+		log.Printf("Retry after %v", delay)
+	}
+}
+
+func doBigCalculate(c calculatorpb.CalculatorServiceClient, op calculatorpb.BigOperation, first string, second string) {
+	log.Println("Starting to do a Unary - BigCalculate")
+
+	res, err := c.BigCalculate(context.Background(), &calculatorpb.BigCalculateRequest{
+		Operation:    op,
+		FirstNumber:  first,
+		SecondNumber: second,
+		Precision:    50,
+	})
 	if err != nil {
-		log.Fatalf("error while calling ComputeAverage: %v", err)
+		logError("BigCalculate", err)
+		return
 	}
 
-	numbers := []int32{12, 4, 23, 13, 20}
+	fmt.Printf("Response from BigCalculate: %s (exact: %v)\n", res.GetResult(), res.GetExact())
+}
 
-	for _, number := range numbers {
-		fmt.Printf("Sendingn number: %d\n", number)
-		if err := stream.Send(&calculatorpb.ComputeAverageRequest{
-			Number: number,
-		}); err != nil {
-			log.Printf("Error sending number: %s", err.Error())
+func doEvaluate(c calculatorpb.CalculatorServiceClient, expression string, variables map[string]float64) {
+	log.Println("Starting to do a Unary - Evaluate")
+
+	res, err := c.Evaluate(context.Background(), &calculatorpb.EvaluateRequest{
+		Expression: expression,
+		Variables:  variables,
+	})
+	if err != nil {
+		logError("Evaluate", err)
+		return
+	}
+
+	fmt.Printf("Response from Evaluate: %v\n", res.GetResult())
+}
+
+func doComputeStatistics(c calculatorpb.CalculatorServiceClient) {
+	log.Println("Starting to do a Client Streaming - ComputeStatistics")
+
+	stream, err := c.ComputeStatistics(context.Background())
+	if err != nil {
+		logError("ComputeStatistics", err)
+		return
+	}
+
+	values := []float64{12.5, 4, 23, 13.25, 20, 1e6, -3}
+
+	for i, value := range values {
+		req := &calculatorpb.ComputeStatisticsRequest{
+			Value: value,
+		}
+		if i == 0 {
+			req.Percentiles = []float64{25, 75, 95}
+		}
+		if err := stream.Send(req); err != nil {
+			// CloseAndRecv returns the error of the call
+			break
 		}
 	}
 
 	res, err := stream.CloseAndRecv()
 	if err != nil {
-		log.Fatalf("error while receiving ComputeAverage response: %v", err)
+		logError("ComputeStatistics", err)
+		return
 	}
 
-	log.Printf("Response from Compute: %v", res.GetAverage())
+	printStatistics(res)
 }
 
-func doBiDiStreaming(c calculatorpb.CalculatorServiceClient) {
-	log.Println("Starting to do a BiDi Streaming")
+func doRunningStatistics(c calculatorpb.CalculatorServiceClient, emitEvery int32) {
+	log.Println("Starting to do a BiDi Streaming - RunningStatistics")
 
-	stream, err := c.FindMaximum(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.RunningStatistics(ctx)
 	if err != nil {
-		log.Fatalf("error while calling FindMaximum: %v", err)
+		logError("RunningStatistics", err)
+		return
 	}
 
 	waitc := make(chan struct{})
 
-	// Send number to the server
 	go func() {
-		numbers := []int32{3, 7, 2, 15, 22, 10, 8, 24, 1}
+		for i := 1; i <= 10; i++ {
+			req := &calculatorpb.ComputeStatisticsRequest{
+				Value: float64(i * i),
+			}
+			if i == 1 {
+				req.EmitEvery = emitEvery
+			}
+			if err := stream.Send(req); err != nil {
+				// Recv returns the error of the call
+				return
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+		if err := stream.CloseSend(); err != nil {
+			log.Printf("Error to close send: %s", err.Error())
+		}
+	}()
 
-		for _, number := range numbers {
-			if err := stream.Send(&calculatorpb.FindMaximumRequest{
-				Number: number,
-			}); err != nil {
-				log.Printf("Error sending number: %s", err.Error())
+	go func() {
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				logError("RunningStatistics", err)
+				break
 			}
-			fmt.Printf("Sending maximum: %d\n", number)
-			time.Sleep(1000 * time.Millisecond)
+
+			printStatistics(res)
+		}
+		close(waitc)
+	}()
+
+	<-waitc
+}
+
+func doAggregate(c calculatorpb.CalculatorServiceClient, first *calculatorpb.AggregateRequest) {
+	log.Println("Starting to do a BiDi Streaming - Aggregate")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.Aggregate(ctx)
+	if err != nil {
+		logError("Aggregate", err)
+		return
+	}
+
+	waitc := make(chan struct{})
+
+	go func() {
+		values := []float64{-3, -7, -2, -15, -22, -10, -8, -24, -1}
+
+		for i, value := range values {
+			req := &calculatorpb.AggregateRequest{
+				Value: value,
+			}
+			if i == 0 {
+				req = first
+				req.Value = value
+			}
+			if err := stream.Send(req); err != nil {
+				// Recv returns the error of the call
+				return
+			}
+			fmt.Printf("Sending value: %v\n", value)
+			time.Sleep(300 * time.Millisecond)
 		}
 		if err := stream.CloseSend(); err != nil {
 			log.Printf("Error to close send: %s", err.Error())
 		}
 	}()
 
-	// Receive max from server
 	go func() {
 		for {
 			res, err := stream.Recv()
 			if err == io.EOF {
 				break
 			} else if err != nil {
-				log.Fatalf("error while reading stream: %v", err)
+				logError("Aggregate", err)
+				break
 			}
 
-			fmt.Printf("Received maximum: %d\n", res.GetMaximum())
+			fmt.Printf("Window [%d, %d): %v (%d values, partial: %v)\n",
+				res.GetWindowStart(), res.GetWindowEnd(), res.GetResult(), res.GetCount(), res.GetPartial())
 		}
 		close(waitc)
 	}()
 
 	<-waitc
+}
+
+func doComputeMatrix(c calculatorpb.CalculatorServiceClient, op calculatorpb.MatrixOperation, a [][]float64, b [][]float64) {
+	log.Println("Starting to do a BiDi Streaming - ComputeMatrix")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.ComputeMatrix(ctx)
+	if err != nil {
+		logError("ComputeMatrix", err)
+		return
+	}
 
+	go func() {
+		for i, row := range a {
+			req := &calculatorpb.MatrixRequest{
+				Row: &calculatorpb.MatrixRequest_A{A: &calculatorpb.MatrixRow{Values: row}},
+			}
+			if i == 0 {
+				req.Operation = op
+			}
+			if err := stream.Send(req); err != nil {
+				// Recv returns the error of the call
+				return
+			}
+		}
+		for _, row := range b {
+			if err := stream.Send(&calculatorpb.MatrixRequest{
+				Row: &calculatorpb.MatrixRequest_B{B: &calculatorpb.MatrixRow{Values: row}},
+			}); err != nil {
+				// Recv returns the error of the call
+				return
+			}
+		}
+		if err := stream.CloseSend(); err != nil {
+			log.Printf("Error to close send: %s", err.Error())
+		}
+	}()
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			logError("ComputeMatrix", err)
+			return
+		}
+
+		switch result := res.GetResult().(type) {
+		case *calculatorpb.MatrixResponse_Row:
+			fmt.Printf("Row: %v\n", result.Row.GetValues())
+		case *calculatorpb.MatrixResponse_Determinant:
+			fmt.Printf("Determinant: %v\n", result.Determinant)
+		}
+	}
 }
 
-func doSquareRoot(c calculatorpb.CalculatorServiceClient, n int32) {
-	log.Println("Starting to do a Unary - SquareRoot")
+func doConvert(c calculatorpb.CalculatorServiceClient, value float64, from string, to string) {
+	log.Println("Starting to do a Unary - Convert")
 
-	res, err := c.SquareRoot(context.Background(), &calculatorpb.SquareRootRequest{
-		Number: n,
+	res, err := c.Convert(context.Background(), &calculatorpb.ConvertRequest{
+		Value: value,
+		From:  from,
+		To:    to,
 	})
+	if err != nil {
+		logError("Convert", err)
+		return
+	}
+
+	fmt.Printf("Response from Convert: %v %s = %v %s (%s)\n", value, from, res.GetValue(), to, res.GetDimension())
+}
+
+func printStatistics(res *calculatorpb.ComputeStatisticsResponse) {
+	fmt.Printf("count: %d, sum: %v, mean: %v, stddev: %v, min: %v, max: %v, median: %v\n",
+		res.GetCount(), res.GetSum(), res.GetMean(), res.GetStddev(), res.GetMin(), res.GetMax(), res.GetMedian())
+	for _, p := range res.GetPercentiles() {
+		fmt.Printf("  p%v: %v\n", p.GetPercentile(), p.GetValue())
+	}
+}
+
+func doSubmitJob(c calculatorpb.CalculatorServiceClient, req *calculatorpb.SubmitJobRequest) {
+	log.Println("Starting to do a Unary - SubmitJob")
 
+	job, err := c.SubmitJob(context.Background(), req)
 	if err != nil {
-		respErr, ok := status.FromError(err)
-		if ok {
-			// user error
-			log.Printf("Error::Code::%d::Message::%s", respErr.Code(), respErr.Message())
-			if respErr.Code() == codes.InvalidArgument {
-				log.Println("Negative number not allowed!")
-			}
-		} else {
-			// framework error
-			log.Fatalf("error while calling SquareRoot: %v", err)
+		logError("SubmitJob", err)
+		return
+	}
+	fmt.Printf("Job %s is %v\n", job.GetId(), job.GetState())
+
+	// WaitJob returns after its timeout even when the job is not done,
+	// to report its progress
+	for job.GetEndTime() == nil {
+		job, err = c.WaitJob(context.Background(), &calculatorpb.WaitJobRequest{
+			Id:      job.GetId(),
+			Timeout: durationpb.New(time.Second),
+		})
+		if err != nil {
+			logError("WaitJob", err)
+			return
 		}
+		fmt.Printf("Job %s is %v, %.0f%% done\n", job.GetId(), job.GetState(), job.GetProgress()*100)
+	}
 
-	} else {
-		fmt.Printf("SUCCESS::\nResponse from SquareRoot: %v\n", res.GetNumberRoot())
+	switch res := job.GetResult().(type) {
+	case *calculatorpb.Job_PrimeFactors:
+		for _, f := range res.PrimeFactors.GetFactors() {
+			fmt.Printf("Prime factor: %s^%d\n", f.GetBigPrimeFactor(), f.GetMultiplicity())
+		}
+	case *calculatorpb.Job_Matrix:
+		for _, row := range res.Matrix.GetRows() {
+			fmt.Println(row.GetValues())
+		}
+		fmt.Printf("Determinant: %v\n", res.Matrix.GetDeterminant())
+	case *calculatorpb.Job_Error:
+		fmt.Printf("Job error: %v %s\n", codes.Code(res.Error.GetCode()), res.Error.GetMessage())
 	}
 }
@@ -1,127 +1,22 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"io"
 	"log"
-	"math"
-	"net"
 
-	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/reflection"
-	"google.golang.org/grpc/status"
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorserver"
+	"github.com/pjserol/tuto-grpc-go/server"
 )
 
-type server struct{}
-
-func (*server) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
-	log.Printf("Sum function invoked with %v \n", req)
-	return &calculatorpb.SumResponse{
-		SumResult: req.FirstNumber + req.SecondNumber,
-	}, nil
-}
-
-func (*server) PrimeNumberDecomposition(req *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.CalculatorService_PrimeNumberDecompositionServer) error {
-	log.Printf("PrimeNumberDecomposition function invoked with %v \n", req)
-	nb := req.GetNumber()
-	var divisor int64 = 2
-
-	for nb > 1 {
-		if nb%divisor == 0 {
-			stream.Send(
-				&calculatorpb.PrimeNumberDecompositionResponse{
-					PrimeFactor: divisor,
-				},
-			)
-
-			nb = nb / divisor
-		} else {
-			divisor++
-		}
-	}
-	return nil
-}
-
-func (*server) ComputeAverage(stream calculatorpb.CalculatorService_ComputeAverageServer) error {
-	log.Println("ComputeAverage function")
-
-	var sum int32
-	count := 0
-
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			return stream.SendAndClose(&calculatorpb.ComputeAverageResponse{
-				Average: float64(sum) / float64(count),
-			})
-		} else if err != nil {
-			log.Fatalf("error while reading client stream: %v", err)
-		}
-
-		sum += req.GetNumber()
-		count++
-	}
-}
-
-func (*server) FindMaximum(stream calculatorpb.CalculatorService_FindMaximumServer) error {
-	log.Println("FindMaximum function")
-
-	var max int32
-
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			return nil
-		} else if err != nil {
-			return err
-		}
-
-		number := req.GetNumber()
-		if number > max {
-			max = number
-			if err := stream.Send(&calculatorpb.FindMaximumResponse{
-				Maximum: max,
-			}); err != nil {
-				return err
-			}
-		}
-	}
-}
-
-func (*server) SquareRoot(ctx context.Context, req *calculatorpb.SquareRootRequest) (*calculatorpb.SquareRootResponse, error) {
-	log.Printf("SquareRoot function invoked with %v \n", req)
-	number := req.GetNumber()
-
-	if number < 0 {
-		return nil, status.Errorf(
-			codes.InvalidArgument,
-			fmt.Sprintf("Received a negative number: %d", number),
-		)
-	}
-
-	return &calculatorpb.SquareRootResponse{
-		NumberRoot: math.Sqrt(float64(number)),
-	}, nil
-}
-
 func main() {
 	fmt.Println("Start server!")
 
-	lis, err := net.Listen("tcp", "0.0.0.0:50051")
+	service, err := calculatorserver.Service()
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		log.Fatalf("failed to configure the service: %v", err)
 	}
 
-	s := grpc.NewServer()
-	calculatorpb.RegisterCalculatorServiceServer(s, &server{})
-
-	// Register reflection service on gRPC server.
-	reflection.Register(s)
-
-	if err := s.Serve(lis); err != nil {
+	if err := server.Run(server.Options{}, service); err != nil {
 		log.Fatalf("failed to serve: %v ", err)
 	}
 }
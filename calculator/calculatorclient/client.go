@@ -0,0 +1,399 @@
+// Package calculatorclient is a client of the CalculatorService, with
+// typed methods, retries of the calls failed with Unavailable and typed
+// errors:
+//
+//	cc, err := grpc.Dial("localhost:50051", grpc.WithInsecure())
+//	...
+//	c := calculatorclient.New(cc, calculatorclient.Options{})
+//	sum, err := c.Sum(ctx, 10, 25)
+//
+// The errors of the methods are *Error.
+package calculatorclient
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = 250 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// Options configures the Client.
+type Options struct {
+	// MaxRetries is the number of retries of a call failed with
+	// Unavailable, 3 when zero. A negative MaxRetries disables the retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry, 250ms when zero,
+	// doubled after each retry up to MaxBackoff, 5s when zero. A longer
+	// retry delay sent by the server is used instead.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Client calls the CalculatorService. It is safe for concurrent use.
+type Client struct {
+	c    calculatorpb.CalculatorServiceClient
+	opts Options
+}
+
+// New returns a Client of the CalculatorService served on cc.
+func New(cc grpc.ClientConnInterface, opts Options) *Client {
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.Backoff == 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+
+	return &Client{
+		c:    calculatorpb.NewCalculatorServiceClient(cc),
+		opts: opts,
+	}
+}
+
+// backoff waits before the retry attempt, from 1, of a call failed with
+// err, and reports whether to retry it.
+func (c *Client) backoff(ctx context.Context, attempt int, err error) bool {
+	if status.Code(err) != codes.Unavailable || attempt > c.opts.MaxRetries {
+		return false
+	}
+
+	timer := time.NewTimer(c.delay(attempt, err))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// delay returns how long to wait before the retry attempt of a call
+// failed with err.
+func (c *Client) delay(attempt int, err error) time.Duration {
+	delay := c.opts.Backoff
+	for i := 1; i < attempt && delay < c.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.opts.MaxBackoff {
+		delay = c.opts.MaxBackoff
+	}
+	// jitter, so that the clients disconnected together do not retry
+	// together
+	delay += time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	if e := grpcerr.ClientError(err).(*Error); e.RetryDelay > delay {
+		delay = e.RetryDelay
+	}
+	return delay
+}
+
+// call runs call, the calls being idempotent, until it does not fail with
+// Unavailable.
+func (c *Client) call(ctx context.Context, call func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil {
+			return nil
+		}
+		if !c.backoff(ctx, attempt, err) {
+			return grpcerr.ClientError(err)
+		}
+	}
+}
+
+// Sum returns a + b, an OutOfRange error when it overflows int32.
+func (c *Client) Sum(ctx context.Context, a int32, b int32) (int32, error) {
+	var res *calculatorpb.SumResponse
+	err := c.call(ctx, func() (err error) {
+		res, err = c.c.Sum(ctx, &calculatorpb.SumRequest{FirstNumber: a, SecondNumber: b})
+		return err
+	})
+	return res.GetSumResult(), err
+}
+
+// SquareRoot returns the square root of n, an InvalidArgument error when n
+// is negative.
+func (c *Client) SquareRoot(ctx context.Context, n int32) (float64, error) {
+	var res *calculatorpb.SquareRootResponse
+	err := c.call(ctx, func() (err error) {
+		res, err = c.c.SquareRoot(ctx, &calculatorpb.SquareRootRequest{Number: n})
+		return err
+	})
+	return res.GetNumberRoot(), err
+}
+
+// Factor is a prime factor and its multiplicity.
+type Factor struct {
+	Prime        *big.Int
+	Multiplicity int
+}
+
+// Factors iterates over the prime factors of a number, in increasing
+// order, as the server streams them:
+//
+//	factors := c.Factorize(ctx, n)
+//	for factors.Next() {
+//		f := factors.Factor()
+//		...
+//	}
+//	if err := factors.Err(); err != nil {
+//
+// Cancel ctx to stop the factorization before its end.
+type Factors struct {
+	c      *Client
+	ctx    context.Context
+	req    *calculatorpb.PrimeNumberDecompositionRequest
+	stream calculatorpb.CalculatorService_PrimeNumberDecompositionClient
+
+	attempt  int
+	received bool
+	done     bool
+	factor   Factor
+	err      error
+}
+
+// Factorize returns the prime factors of n. The call is retried when it
+// fails with Unavailable before the first factor is received.
+func (c *Client) Factorize(ctx context.Context, n *big.Int) *Factors {
+	req := &calculatorpb.PrimeNumberDecompositionRequest{}
+	if n.IsInt64() {
+		req.Number = n.Int64()
+	} else {
+		req.BigNumber = n.String()
+	}
+	return &Factors{c: c, ctx: ctx, req: req}
+}
+
+// Next receives the next factor, and reports whether there is one.
+func (f *Factors) Next() bool {
+	if f.done {
+		return false
+	}
+
+	for {
+		var err error
+		if f.stream == nil {
+			f.stream, err = f.c.c.PrimeNumberDecomposition(f.ctx, f.req)
+		}
+		var res *calculatorpb.PrimeNumberDecompositionResponse
+		if err == nil {
+			res, err = f.stream.Recv()
+		}
+		if err == io.EOF {
+			f.done = true
+			return false
+		} else if err != nil {
+			f.stream = nil
+			f.attempt++
+			if !f.received && f.c.backoff(f.ctx, f.attempt, err) {
+				continue
+			}
+			f.done = true
+			f.err = grpcerr.ClientError(err)
+			return false
+		}
+
+		prime, ok := new(big.Int).SetString(res.GetBigPrimeFactor(), 10)
+		if !ok {
+			prime = big.NewInt(res.GetPrimeFactor())
+		}
+		f.received = true
+		f.factor = Factor{Prime: prime, Multiplicity: int(res.GetMultiplicity())}
+		return true
+	}
+}
+
+// Factor returns the factor received by Next.
+func (f *Factors) Factor() Factor {
+	return f.factor
+}
+
+// Err returns the error that ended the iteration, nil at the end of the
+// factors.
+func (f *Factors) Err() error {
+	return f.err
+}
+
+// Average returns the average of numbers, an InvalidArgument error when
+// it is empty.
+func (c *Client) Average(ctx context.Context, numbers []int32) (float64, error) {
+	var average float64
+	err := c.call(ctx, func() (err error) {
+		i := 0
+		average, err = c.average(ctx, func() (int32, bool, error) {
+			if i == len(numbers) {
+				return 0, false, nil
+			}
+			i++
+			return numbers[i-1], true, nil
+		})
+		return err
+	})
+	return average, err
+}
+
+// AverageStream returns the average of the numbers received on numbers
+// until it is closed. The call is not retried, the numbers sent being
+// lost.
+func (c *Client) AverageStream(ctx context.Context, numbers <-chan int32) (float64, error) {
+	average, err := c.average(ctx, func() (int32, bool, error) {
+		select {
+		case <-ctx.Done():
+			return 0, false, ctx.Err()
+		case n, ok := <-numbers:
+			return n, ok, nil
+		}
+	})
+	if err == ctx.Err() && err != nil {
+		return 0, contextError(ctx)
+	}
+	return average, grpcerr.ClientError(err)
+}
+
+// average streams the numbers returned by next, until it returns false,
+// to ComputeAverage.
+func (c *Client) average(ctx context.Context, next func() (int32, bool, error)) (float64, error) {
+	stream, err := c.c.ComputeAverage(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		n, ok, err := next()
+		if err != nil {
+			return 0, err
+		} else if !ok {
+			break
+		}
+		if err := stream.Send(&calculatorpb.ComputeAverageRequest{Number: n}); err == io.EOF {
+			// the server ended the call, CloseAndRecv returns its error
+			break
+		} else if err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, err
+	}
+	return res.GetAverage(), nil
+}
+
+// Maximums iterates over the maximums of a stream of numbers, each
+// received when a number greater than the previous ones is sent:
+//
+//	maximums := c.Max(ctx, numbers)
+//	for maximums.Next() {
+//		max := maximums.Maximum()
+//		...
+//	}
+//	if err := maximums.Err(); err != nil {
+type Maximums struct {
+	c       *Client
+	ctx     context.Context
+	cancel  context.CancelFunc
+	numbers <-chan int32
+	stream  calculatorpb.CalculatorService_FindMaximumClient
+
+	done    bool
+	maximum int32
+	err     error
+}
+
+// Max streams the numbers received on numbers, until it is closed, to
+// FindMaximum. Opening the stream is retried when it fails with
+// Unavailable, not the stream itself, the numbers sent being lost.
+func (c *Client) Max(ctx context.Context, numbers <-chan int32) *Maximums {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Maximums{c: c, ctx: ctx, cancel: cancel, numbers: numbers}
+}
+
+// open opens the stream, and starts sending the numbers.
+func (m *Maximums) open() error {
+	err := m.c.call(m.ctx, func() (err error) {
+		m.stream, err = m.c.c.FindMaximum(m.ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case n, ok := <-m.numbers:
+				if !ok {
+					m.stream.CloseSend()
+					return
+				}
+				// on error, Recv returns the status of the stream
+				if err := m.stream.Send(&calculatorpb.FindMaximumRequest{Number: n}); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Next receives the next maximum, and reports whether there is one.
+func (m *Maximums) Next() bool {
+	if m.done {
+		return false
+	}
+
+	if m.stream == nil {
+		if err := m.open(); err != nil {
+			m.finish(err)
+			return false
+		}
+	}
+
+	res, err := m.stream.Recv()
+	if err == io.EOF {
+		m.finish(nil)
+		return false
+	} else if err != nil {
+		m.finish(grpcerr.ClientError(err))
+		return false
+	}
+
+	m.maximum = res.GetMaximum()
+	return true
+}
+
+// finish ends the iteration with err, and stops sending the numbers.
+func (m *Maximums) finish(err error) {
+	m.done = true
+	m.err = err
+	m.cancel()
+}
+
+// Maximum returns the maximum received by Next.
+func (m *Maximums) Maximum() int32 {
+	return m.maximum
+}
+
+// Err returns the error that ended the iteration, nil at the end of the
+// stream.
+func (m *Maximums) Err() error {
+	return m.err
+}
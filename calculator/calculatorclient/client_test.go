@@ -0,0 +1,235 @@
+package calculatorclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// calculatorService fails the first calls, then answers them.
+type calculatorService struct {
+	calculatorpb.CalculatorServiceClient
+	// failures is the number of calls failed with err
+	failures int
+	err      error
+	// factors are streamed by PrimeNumberDecomposition, followed by
+	// streamErr, io.EOF when nil
+	factors   []int64
+	streamErr error
+
+	calls int
+}
+
+func (s *calculatorService) Sum(ctx context.Context, req *calculatorpb.SumRequest, opts ...grpc.CallOption) (*calculatorpb.SumResponse, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, s.err
+	}
+	return &calculatorpb.SumResponse{SumResult: req.GetFirstNumber() + req.GetSecondNumber()}, nil
+}
+
+func (s *calculatorService) PrimeNumberDecomposition(ctx context.Context, req *calculatorpb.PrimeNumberDecompositionRequest, opts ...grpc.CallOption) (calculatorpb.CalculatorService_PrimeNumberDecompositionClient, error) {
+	s.calls++
+	if s.calls <= s.failures {
+		return nil, s.err
+	}
+	stream := &factorStream{err: s.streamErr}
+	for _, f := range s.factors {
+		stream.responses = append(stream.responses, &calculatorpb.PrimeNumberDecompositionResponse{PrimeFactor: f, Multiplicity: 1})
+	}
+	return stream, nil
+}
+
+type factorStream struct {
+	grpc.ClientStream
+	responses []*calculatorpb.PrimeNumberDecompositionResponse
+	err       error
+}
+
+func (s *factorStream) Recv() (*calculatorpb.PrimeNumberDecompositionResponse, error) {
+	if len(s.responses) == 0 {
+		if s.err == nil {
+			return nil, io.EOF
+		}
+		return nil, s.err
+	}
+	res := s.responses[0]
+	s.responses = s.responses[1:]
+	return res, nil
+}
+
+// newClient returns a Client of s, retrying without waiting much.
+func newClient(s *calculatorService, opts Options) *Client {
+	if opts.Backoff == 0 {
+		opts.Backoff = time.Millisecond
+	}
+	c := New(nil, opts)
+	c.c = s
+	return c
+}
+
+func TestDelay(t *testing.T) {
+	c := New(nil, Options{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second})
+	unavailable := grpcerr.Unavailable("unavailable", 0)
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		// want is the delay without the jitter
+		want time.Duration
+		// exact is set when the delay of the server is used
+		exact bool
+	}{
+		{name: "first retry", attempt: 1, err: unavailable, want: 100 * time.Millisecond},
+		{name: "doubled", attempt: 3, err: unavailable, want: 400 * time.Millisecond},
+		{name: "capped", attempt: 5, err: unavailable, want: time.Second},
+		{name: "capped after many retries", attempt: 100, err: unavailable, want: time.Second},
+		{name: "delay of the server", attempt: 1, err: grpcerr.Unavailable("unavailable", 3*time.Second), want: 3 * time.Second, exact: true},
+		{name: "shorter delay of the server", attempt: 2, err: grpcerr.Unavailable("unavailable", time.Millisecond), want: 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got := c.delay(tt.attempt, tt.err)
+				max := tt.want + tt.want/5
+				if tt.exact {
+					max = tt.want
+				}
+				if got < tt.want || got > max {
+					t.Fatalf("delay(%d) = %v, want between %v and %v", tt.attempt, got, tt.want, max)
+				}
+			}
+		})
+	}
+}
+
+func TestCallRetries(t *testing.T) {
+	unavailable := grpcerr.Unavailable("unavailable", 0)
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		failures   int
+		err        error
+		wantCalls  int
+		wantCode   codes.Code
+	}{
+		{name: "no failure", wantCalls: 1},
+		{name: "retried", failures: 2, err: unavailable, wantCalls: 3},
+		{name: "last retry", failures: 3, err: unavailable, wantCalls: 4},
+		{name: "too many failures", failures: 4, err: unavailable, wantCalls: 4, wantCode: codes.Unavailable},
+		{name: "more retries", maxRetries: 5, failures: 4, err: unavailable, wantCalls: 5},
+		{name: "retries disabled", maxRetries: -1, failures: 1, err: unavailable, wantCalls: 1, wantCode: codes.Unavailable},
+		{name: "not unavailable", failures: 1, err: grpcerr.OutOfRange("sum", "overflow"), wantCalls: 1, wantCode: codes.OutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &calculatorService{failures: tt.failures, err: tt.err}
+			sum, err := newClient(s, Options{MaxRetries: tt.maxRetries}).Sum(context.Background(), 1, 2)
+
+			if s.calls != tt.wantCalls {
+				t.Errorf("%d calls, want %d", s.calls, tt.wantCalls)
+			}
+			if tt.wantCode == codes.OK {
+				if err != nil || sum != 3 {
+					t.Errorf("Sum() = %v, %v, want 3", sum, err)
+				}
+				return
+			}
+			var e *Error
+			if !errors.As(err, &e) || e.Code != tt.wantCode {
+				t.Errorf("Sum() = %v, want code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCallCanceled(t *testing.T) {
+	// the server asks to wait longer than the test
+	s := &calculatorService{failures: 2, err: grpcerr.Unavailable("unavailable", time.Hour)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := newClient(s, Options{}).Sum(ctx, 1, 2)
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Sum() = %v, want %v", err, ErrUnavailable)
+	}
+	if s.calls != 1 {
+		t.Errorf("%d calls, want 1", s.calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Sum() returned after %v", elapsed)
+	}
+}
+
+func TestFactorizeRetries(t *testing.T) {
+	unavailable := grpcerr.Unavailable("unavailable", 0)
+
+	tests := []struct {
+		name      string
+		s         *calculatorService
+		want      []int64
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "retried before the first factor",
+			s:         &calculatorService{failures: 2, err: unavailable, factors: []int64{2, 3}},
+			want:      []int64{2, 3},
+			wantCalls: 3,
+		},
+		{
+			name:      "stream failed before the first factor",
+			s:         &calculatorService{streamErr: unavailable},
+			wantCalls: 4,
+			wantErr:   ErrUnavailable,
+		},
+		{
+			name:      "not retried after a factor",
+			s:         &calculatorService{factors: []int64{2}, streamErr: unavailable},
+			want:      []int64{2},
+			wantCalls: 1,
+			wantErr:   ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factors := newClient(tt.s, Options{}).Factorize(context.Background(), big.NewInt(6))
+
+			var got []int64
+			for factors.Next() {
+				got = append(got, factors.Factor().Prime.Int64())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("factors = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("factors = %v, want %v", got, tt.want)
+				}
+			}
+			if tt.s.calls != tt.wantCalls {
+				t.Errorf("%d calls, want %d", tt.s.calls, tt.wantCalls)
+			}
+			if err := factors.Err(); err != tt.wantErr && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Err() = %v, want %v", err, tt.wantErr)
+			}
+			if factors.Next() {
+				t.Error("Next() after the end = true")
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package calculatorclient
+
+import (
+	"context"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors matched by errors.Is on their Code, e.g.
+//
+//	if errors.Is(err, calculatorclient.ErrInvalidArgument) {
+var (
+	ErrInvalidArgument   = &Error{Code: codes.InvalidArgument}
+	ErrOutOfRange        = &Error{Code: codes.OutOfRange}
+	ErrUnavailable       = &Error{Code: codes.Unavailable}
+	ErrResourceExhausted = &Error{Code: codes.ResourceExhausted}
+)
+
+// Error is the error of a call, with the details sent by the server.
+type Error = grpcerr.Error
+
+// Violation is a field of the request and why it was rejected.
+type Violation = grpcerr.Violation
+
+// contextError returns the *Error of the context done.
+func contextError(ctx context.Context) error {
+	return grpcerr.ClientError(status.FromContextError(ctx.Err()).Err())
+}
@@ -0,0 +1,614 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: calculator/calculatorpb/calculator.proto
+
+package calculatorpbconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	calculatorpb "github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// CalculatorServiceName is the fully-qualified name of the CalculatorService service.
+	CalculatorServiceName = "calculator.CalculatorService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// CalculatorServiceSumProcedure is the fully-qualified name of the CalculatorService's Sum RPC.
+	CalculatorServiceSumProcedure = "/calculator.CalculatorService/Sum"
+	// CalculatorServicePrimeNumberDecompositionProcedure is the fully-qualified name of the
+	// CalculatorService's PrimeNumberDecomposition RPC.
+	CalculatorServicePrimeNumberDecompositionProcedure = "/calculator.CalculatorService/PrimeNumberDecomposition"
+	// CalculatorServiceComputeAverageProcedure is the fully-qualified name of the CalculatorService's
+	// ComputeAverage RPC.
+	CalculatorServiceComputeAverageProcedure = "/calculator.CalculatorService/ComputeAverage"
+	// CalculatorServiceFindMaximumProcedure is the fully-qualified name of the CalculatorService's
+	// FindMaximum RPC.
+	CalculatorServiceFindMaximumProcedure = "/calculator.CalculatorService/FindMaximum"
+	// CalculatorServiceSquareRootProcedure is the fully-qualified name of the CalculatorService's
+	// SquareRoot RPC.
+	CalculatorServiceSquareRootProcedure = "/calculator.CalculatorService/SquareRoot"
+	// CalculatorServiceBigCalculateProcedure is the fully-qualified name of the CalculatorService's
+	// BigCalculate RPC.
+	CalculatorServiceBigCalculateProcedure = "/calculator.CalculatorService/BigCalculate"
+	// CalculatorServiceEvaluateProcedure is the fully-qualified name of the CalculatorService's
+	// Evaluate RPC.
+	CalculatorServiceEvaluateProcedure = "/calculator.CalculatorService/Evaluate"
+	// CalculatorServiceComputeStatisticsProcedure is the fully-qualified name of the
+	// CalculatorService's ComputeStatistics RPC.
+	CalculatorServiceComputeStatisticsProcedure = "/calculator.CalculatorService/ComputeStatistics"
+	// CalculatorServiceRunningStatisticsProcedure is the fully-qualified name of the
+	// CalculatorService's RunningStatistics RPC.
+	CalculatorServiceRunningStatisticsProcedure = "/calculator.CalculatorService/RunningStatistics"
+	// CalculatorServiceAggregateProcedure is the fully-qualified name of the CalculatorService's
+	// Aggregate RPC.
+	CalculatorServiceAggregateProcedure = "/calculator.CalculatorService/Aggregate"
+	// CalculatorServiceComputeMatrixProcedure is the fully-qualified name of the CalculatorService's
+	// ComputeMatrix RPC.
+	CalculatorServiceComputeMatrixProcedure = "/calculator.CalculatorService/ComputeMatrix"
+	// CalculatorServiceConvertProcedure is the fully-qualified name of the CalculatorService's Convert
+	// RPC.
+	CalculatorServiceConvertProcedure = "/calculator.CalculatorService/Convert"
+	// CalculatorServiceSubmitJobProcedure is the fully-qualified name of the CalculatorService's
+	// SubmitJob RPC.
+	CalculatorServiceSubmitJobProcedure = "/calculator.CalculatorService/SubmitJob"
+	// CalculatorServiceGetJobProcedure is the fully-qualified name of the CalculatorService's GetJob
+	// RPC.
+	CalculatorServiceGetJobProcedure = "/calculator.CalculatorService/GetJob"
+	// CalculatorServiceCancelJobProcedure is the fully-qualified name of the CalculatorService's
+	// CancelJob RPC.
+	CalculatorServiceCancelJobProcedure = "/calculator.CalculatorService/CancelJob"
+	// CalculatorServiceWaitJobProcedure is the fully-qualified name of the CalculatorService's WaitJob
+	// RPC.
+	CalculatorServiceWaitJobProcedure = "/calculator.CalculatorService/WaitJob"
+	// CalculatorServiceListJobsProcedure is the fully-qualified name of the CalculatorService's
+	// ListJobs RPC.
+	CalculatorServiceListJobsProcedure = "/calculator.CalculatorService/ListJobs"
+)
+
+// CalculatorServiceClient is a client for the calculator.CalculatorService service.
+type CalculatorServiceClient interface {
+	Sum(context.Context, *connect.Request[calculatorpb.SumRequest]) (*connect.Response[calculatorpb.SumResponse], error)
+	PrimeNumberDecomposition(context.Context, *connect.Request[calculatorpb.PrimeNumberDecompositionRequest]) (*connect.ServerStreamForClient[calculatorpb.PrimeNumberDecompositionResponse], error)
+	ComputeAverage(context.Context) *connect.ClientStreamForClient[calculatorpb.ComputeAverageRequest, calculatorpb.ComputeAverageResponse]
+	FindMaximum(context.Context) *connect.BidiStreamForClient[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse]
+	// error handling
+	// This RPC will throw an exception if the sent number is negative
+	// The error being sent is of type INVALID_ARGUMENT
+	SquareRoot(context.Context, *connect.Request[calculatorpb.SquareRootRequest]) (*connect.Response[calculatorpb.SquareRootResponse], error)
+	// Arbitrary-precision arithmetic: the result is OUT_OF_RANGE when it is
+	// too large, INVALID_ARGUMENT for a division by zero or the square root
+	// of a negative number
+	BigCalculate(context.Context, *connect.Request[calculatorpb.BigCalculateRequest]) (*connect.Response[calculatorpb.BigCalculateResponse], error)
+	// The errors in the expression are INVALID_ARGUMENT, with their position
+	// in the BadRequest detail
+	Evaluate(context.Context, *connect.Request[calculatorpb.EvaluateRequest]) (*connect.Response[calculatorpb.EvaluateResponse], error)
+	// Statistics of the stream of values
+	ComputeStatistics(context.Context) *connect.ClientStreamForClient[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse]
+	// Statistics of the values received so far, every emit_every values and
+	// at the end of the stream
+	RunningStatistics(context.Context) *connect.BidiStreamForClient[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse]
+	// Aggregation of the values over tumbling or sliding windows
+	Aggregate(context.Context) *connect.BidiStreamForClient[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse]
+	// Matrix operations: the matrices are streamed row by row, and the result
+	// is streamed row by row once they are received. A singular matrix is
+	// INVALID_ARGUMENT for MATRIX_INVERSE and MATRIX_SOLVE.
+	ComputeMatrix(context.Context) *connect.BidiStreamForClient[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse]
+	// Unit and currency conversion: an unknown unit is INVALID_ARGUMENT, a
+	// currency conversion without rates file is FAILED_PRECONDITION
+	Convert(context.Context, *connect.Request[calculatorpb.ConvertRequest]) (*connect.Response[calculatorpb.ConvertResponse], error)
+	// Submits a job, RESOURCE_EXHAUSTED when the queue of the jobs is full
+	SubmitJob(context.Context, *connect.Request[calculatorpb.SubmitJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	GetJob(context.Context, *connect.Request[calculatorpb.GetJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	// Cancels a queued or running job, FAILED_PRECONDITION when it is
+	// already done
+	CancelJob(context.Context, *connect.Request[calculatorpb.CancelJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	// Returns the job once it is done, or when the timeout is reached
+	WaitJob(context.Context, *connect.Request[calculatorpb.WaitJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	ListJobs(context.Context, *connect.Request[calculatorpb.ListJobsRequest]) (*connect.Response[calculatorpb.ListJobsResponse], error)
+}
+
+// NewCalculatorServiceClient constructs a client for the calculator.CalculatorService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewCalculatorServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) CalculatorServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	calculatorServiceMethods := calculatorpb.File_calculator_calculatorpb_calculator_proto.Services().ByName("CalculatorService").Methods()
+	return &calculatorServiceClient{
+		sum: connect.NewClient[calculatorpb.SumRequest, calculatorpb.SumResponse](
+			httpClient,
+			baseURL+CalculatorServiceSumProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("Sum")),
+			connect.WithClientOptions(opts...),
+		),
+		primeNumberDecomposition: connect.NewClient[calculatorpb.PrimeNumberDecompositionRequest, calculatorpb.PrimeNumberDecompositionResponse](
+			httpClient,
+			baseURL+CalculatorServicePrimeNumberDecompositionProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("PrimeNumberDecomposition")),
+			connect.WithClientOptions(opts...),
+		),
+		computeAverage: connect.NewClient[calculatorpb.ComputeAverageRequest, calculatorpb.ComputeAverageResponse](
+			httpClient,
+			baseURL+CalculatorServiceComputeAverageProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("ComputeAverage")),
+			connect.WithClientOptions(opts...),
+		),
+		findMaximum: connect.NewClient[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse](
+			httpClient,
+			baseURL+CalculatorServiceFindMaximumProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("FindMaximum")),
+			connect.WithClientOptions(opts...),
+		),
+		squareRoot: connect.NewClient[calculatorpb.SquareRootRequest, calculatorpb.SquareRootResponse](
+			httpClient,
+			baseURL+CalculatorServiceSquareRootProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("SquareRoot")),
+			connect.WithClientOptions(opts...),
+		),
+		bigCalculate: connect.NewClient[calculatorpb.BigCalculateRequest, calculatorpb.BigCalculateResponse](
+			httpClient,
+			baseURL+CalculatorServiceBigCalculateProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("BigCalculate")),
+			connect.WithClientOptions(opts...),
+		),
+		evaluate: connect.NewClient[calculatorpb.EvaluateRequest, calculatorpb.EvaluateResponse](
+			httpClient,
+			baseURL+CalculatorServiceEvaluateProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("Evaluate")),
+			connect.WithClientOptions(opts...),
+		),
+		computeStatistics: connect.NewClient[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse](
+			httpClient,
+			baseURL+CalculatorServiceComputeStatisticsProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("ComputeStatistics")),
+			connect.WithClientOptions(opts...),
+		),
+		runningStatistics: connect.NewClient[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse](
+			httpClient,
+			baseURL+CalculatorServiceRunningStatisticsProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("RunningStatistics")),
+			connect.WithClientOptions(opts...),
+		),
+		aggregate: connect.NewClient[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse](
+			httpClient,
+			baseURL+CalculatorServiceAggregateProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("Aggregate")),
+			connect.WithClientOptions(opts...),
+		),
+		computeMatrix: connect.NewClient[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse](
+			httpClient,
+			baseURL+CalculatorServiceComputeMatrixProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("ComputeMatrix")),
+			connect.WithClientOptions(opts...),
+		),
+		convert: connect.NewClient[calculatorpb.ConvertRequest, calculatorpb.ConvertResponse](
+			httpClient,
+			baseURL+CalculatorServiceConvertProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("Convert")),
+			connect.WithClientOptions(opts...),
+		),
+		submitJob: connect.NewClient[calculatorpb.SubmitJobRequest, calculatorpb.Job](
+			httpClient,
+			baseURL+CalculatorServiceSubmitJobProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("SubmitJob")),
+			connect.WithClientOptions(opts...),
+		),
+		getJob: connect.NewClient[calculatorpb.GetJobRequest, calculatorpb.Job](
+			httpClient,
+			baseURL+CalculatorServiceGetJobProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("GetJob")),
+			connect.WithClientOptions(opts...),
+		),
+		cancelJob: connect.NewClient[calculatorpb.CancelJobRequest, calculatorpb.Job](
+			httpClient,
+			baseURL+CalculatorServiceCancelJobProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("CancelJob")),
+			connect.WithClientOptions(opts...),
+		),
+		waitJob: connect.NewClient[calculatorpb.WaitJobRequest, calculatorpb.Job](
+			httpClient,
+			baseURL+CalculatorServiceWaitJobProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("WaitJob")),
+			connect.WithClientOptions(opts...),
+		),
+		listJobs: connect.NewClient[calculatorpb.ListJobsRequest, calculatorpb.ListJobsResponse](
+			httpClient,
+			baseURL+CalculatorServiceListJobsProcedure,
+			connect.WithSchema(calculatorServiceMethods.ByName("ListJobs")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// calculatorServiceClient implements CalculatorServiceClient.
+type calculatorServiceClient struct {
+	sum                      *connect.Client[calculatorpb.SumRequest, calculatorpb.SumResponse]
+	primeNumberDecomposition *connect.Client[calculatorpb.PrimeNumberDecompositionRequest, calculatorpb.PrimeNumberDecompositionResponse]
+	computeAverage           *connect.Client[calculatorpb.ComputeAverageRequest, calculatorpb.ComputeAverageResponse]
+	findMaximum              *connect.Client[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse]
+	squareRoot               *connect.Client[calculatorpb.SquareRootRequest, calculatorpb.SquareRootResponse]
+	bigCalculate             *connect.Client[calculatorpb.BigCalculateRequest, calculatorpb.BigCalculateResponse]
+	evaluate                 *connect.Client[calculatorpb.EvaluateRequest, calculatorpb.EvaluateResponse]
+	computeStatistics        *connect.Client[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse]
+	runningStatistics        *connect.Client[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse]
+	aggregate                *connect.Client[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse]
+	computeMatrix            *connect.Client[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse]
+	convert                  *connect.Client[calculatorpb.ConvertRequest, calculatorpb.ConvertResponse]
+	submitJob                *connect.Client[calculatorpb.SubmitJobRequest, calculatorpb.Job]
+	getJob                   *connect.Client[calculatorpb.GetJobRequest, calculatorpb.Job]
+	cancelJob                *connect.Client[calculatorpb.CancelJobRequest, calculatorpb.Job]
+	waitJob                  *connect.Client[calculatorpb.WaitJobRequest, calculatorpb.Job]
+	listJobs                 *connect.Client[calculatorpb.ListJobsRequest, calculatorpb.ListJobsResponse]
+}
+
+// Sum calls calculator.CalculatorService.Sum.
+func (c *calculatorServiceClient) Sum(ctx context.Context, req *connect.Request[calculatorpb.SumRequest]) (*connect.Response[calculatorpb.SumResponse], error) {
+	return c.sum.CallUnary(ctx, req)
+}
+
+// PrimeNumberDecomposition calls calculator.CalculatorService.PrimeNumberDecomposition.
+func (c *calculatorServiceClient) PrimeNumberDecomposition(ctx context.Context, req *connect.Request[calculatorpb.PrimeNumberDecompositionRequest]) (*connect.ServerStreamForClient[calculatorpb.PrimeNumberDecompositionResponse], error) {
+	return c.primeNumberDecomposition.CallServerStream(ctx, req)
+}
+
+// ComputeAverage calls calculator.CalculatorService.ComputeAverage.
+func (c *calculatorServiceClient) ComputeAverage(ctx context.Context) *connect.ClientStreamForClient[calculatorpb.ComputeAverageRequest, calculatorpb.ComputeAverageResponse] {
+	return c.computeAverage.CallClientStream(ctx)
+}
+
+// FindMaximum calls calculator.CalculatorService.FindMaximum.
+func (c *calculatorServiceClient) FindMaximum(ctx context.Context) *connect.BidiStreamForClient[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse] {
+	return c.findMaximum.CallBidiStream(ctx)
+}
+
+// SquareRoot calls calculator.CalculatorService.SquareRoot.
+func (c *calculatorServiceClient) SquareRoot(ctx context.Context, req *connect.Request[calculatorpb.SquareRootRequest]) (*connect.Response[calculatorpb.SquareRootResponse], error) {
+	return c.squareRoot.CallUnary(ctx, req)
+}
+
+// BigCalculate calls calculator.CalculatorService.BigCalculate.
+func (c *calculatorServiceClient) BigCalculate(ctx context.Context, req *connect.Request[calculatorpb.BigCalculateRequest]) (*connect.Response[calculatorpb.BigCalculateResponse], error) {
+	return c.bigCalculate.CallUnary(ctx, req)
+}
+
+// Evaluate calls calculator.CalculatorService.Evaluate.
+func (c *calculatorServiceClient) Evaluate(ctx context.Context, req *connect.Request[calculatorpb.EvaluateRequest]) (*connect.Response[calculatorpb.EvaluateResponse], error) {
+	return c.evaluate.CallUnary(ctx, req)
+}
+
+// ComputeStatistics calls calculator.CalculatorService.ComputeStatistics.
+func (c *calculatorServiceClient) ComputeStatistics(ctx context.Context) *connect.ClientStreamForClient[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse] {
+	return c.computeStatistics.CallClientStream(ctx)
+}
+
+// RunningStatistics calls calculator.CalculatorService.RunningStatistics.
+func (c *calculatorServiceClient) RunningStatistics(ctx context.Context) *connect.BidiStreamForClient[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse] {
+	return c.runningStatistics.CallBidiStream(ctx)
+}
+
+// Aggregate calls calculator.CalculatorService.Aggregate.
+func (c *calculatorServiceClient) Aggregate(ctx context.Context) *connect.BidiStreamForClient[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse] {
+	return c.aggregate.CallBidiStream(ctx)
+}
+
+// ComputeMatrix calls calculator.CalculatorService.ComputeMatrix.
+func (c *calculatorServiceClient) ComputeMatrix(ctx context.Context) *connect.BidiStreamForClient[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse] {
+	return c.computeMatrix.CallBidiStream(ctx)
+}
+
+// Convert calls calculator.CalculatorService.Convert.
+func (c *calculatorServiceClient) Convert(ctx context.Context, req *connect.Request[calculatorpb.ConvertRequest]) (*connect.Response[calculatorpb.ConvertResponse], error) {
+	return c.convert.CallUnary(ctx, req)
+}
+
+// SubmitJob calls calculator.CalculatorService.SubmitJob.
+func (c *calculatorServiceClient) SubmitJob(ctx context.Context, req *connect.Request[calculatorpb.SubmitJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return c.submitJob.CallUnary(ctx, req)
+}
+
+// GetJob calls calculator.CalculatorService.GetJob.
+func (c *calculatorServiceClient) GetJob(ctx context.Context, req *connect.Request[calculatorpb.GetJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return c.getJob.CallUnary(ctx, req)
+}
+
+// CancelJob calls calculator.CalculatorService.CancelJob.
+func (c *calculatorServiceClient) CancelJob(ctx context.Context, req *connect.Request[calculatorpb.CancelJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return c.cancelJob.CallUnary(ctx, req)
+}
+
+// WaitJob calls calculator.CalculatorService.WaitJob.
+func (c *calculatorServiceClient) WaitJob(ctx context.Context, req *connect.Request[calculatorpb.WaitJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return c.waitJob.CallUnary(ctx, req)
+}
+
+// ListJobs calls calculator.CalculatorService.ListJobs.
+func (c *calculatorServiceClient) ListJobs(ctx context.Context, req *connect.Request[calculatorpb.ListJobsRequest]) (*connect.Response[calculatorpb.ListJobsResponse], error) {
+	return c.listJobs.CallUnary(ctx, req)
+}
+
+// CalculatorServiceHandler is an implementation of the calculator.CalculatorService service.
+type CalculatorServiceHandler interface {
+	Sum(context.Context, *connect.Request[calculatorpb.SumRequest]) (*connect.Response[calculatorpb.SumResponse], error)
+	PrimeNumberDecomposition(context.Context, *connect.Request[calculatorpb.PrimeNumberDecompositionRequest], *connect.ServerStream[calculatorpb.PrimeNumberDecompositionResponse]) error
+	ComputeAverage(context.Context, *connect.ClientStream[calculatorpb.ComputeAverageRequest]) (*connect.Response[calculatorpb.ComputeAverageResponse], error)
+	FindMaximum(context.Context, *connect.BidiStream[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse]) error
+	// error handling
+	// This RPC will throw an exception if the sent number is negative
+	// The error being sent is of type INVALID_ARGUMENT
+	SquareRoot(context.Context, *connect.Request[calculatorpb.SquareRootRequest]) (*connect.Response[calculatorpb.SquareRootResponse], error)
+	// Arbitrary-precision arithmetic: the result is OUT_OF_RANGE when it is
+	// too large, INVALID_ARGUMENT for a division by zero or the square root
+	// of a negative number
+	BigCalculate(context.Context, *connect.Request[calculatorpb.BigCalculateRequest]) (*connect.Response[calculatorpb.BigCalculateResponse], error)
+	// The errors in the expression are INVALID_ARGUMENT, with their position
+	// in the BadRequest detail
+	Evaluate(context.Context, *connect.Request[calculatorpb.EvaluateRequest]) (*connect.Response[calculatorpb.EvaluateResponse], error)
+	// Statistics of the stream of values
+	ComputeStatistics(context.Context, *connect.ClientStream[calculatorpb.ComputeStatisticsRequest]) (*connect.Response[calculatorpb.ComputeStatisticsResponse], error)
+	// Statistics of the values received so far, every emit_every values and
+	// at the end of the stream
+	RunningStatistics(context.Context, *connect.BidiStream[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse]) error
+	// Aggregation of the values over tumbling or sliding windows
+	Aggregate(context.Context, *connect.BidiStream[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse]) error
+	// Matrix operations: the matrices are streamed row by row, and the result
+	// is streamed row by row once they are received. A singular matrix is
+	// INVALID_ARGUMENT for MATRIX_INVERSE and MATRIX_SOLVE.
+	ComputeMatrix(context.Context, *connect.BidiStream[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse]) error
+	// Unit and currency conversion: an unknown unit is INVALID_ARGUMENT, a
+	// currency conversion without rates file is FAILED_PRECONDITION
+	Convert(context.Context, *connect.Request[calculatorpb.ConvertRequest]) (*connect.Response[calculatorpb.ConvertResponse], error)
+	// Submits a job, RESOURCE_EXHAUSTED when the queue of the jobs is full
+	SubmitJob(context.Context, *connect.Request[calculatorpb.SubmitJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	GetJob(context.Context, *connect.Request[calculatorpb.GetJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	// Cancels a queued or running job, FAILED_PRECONDITION when it is
+	// already done
+	CancelJob(context.Context, *connect.Request[calculatorpb.CancelJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	// Returns the job once it is done, or when the timeout is reached
+	WaitJob(context.Context, *connect.Request[calculatorpb.WaitJobRequest]) (*connect.Response[calculatorpb.Job], error)
+	ListJobs(context.Context, *connect.Request[calculatorpb.ListJobsRequest]) (*connect.Response[calculatorpb.ListJobsResponse], error)
+}
+
+// NewCalculatorServiceHandler builds an HTTP handler from the service implementation. It returns
+// the path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewCalculatorServiceHandler(svc CalculatorServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	calculatorServiceMethods := calculatorpb.File_calculator_calculatorpb_calculator_proto.Services().ByName("CalculatorService").Methods()
+	calculatorServiceSumHandler := connect.NewUnaryHandler(
+		CalculatorServiceSumProcedure,
+		svc.Sum,
+		connect.WithSchema(calculatorServiceMethods.ByName("Sum")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServicePrimeNumberDecompositionHandler := connect.NewServerStreamHandler(
+		CalculatorServicePrimeNumberDecompositionProcedure,
+		svc.PrimeNumberDecomposition,
+		connect.WithSchema(calculatorServiceMethods.ByName("PrimeNumberDecomposition")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceComputeAverageHandler := connect.NewClientStreamHandler(
+		CalculatorServiceComputeAverageProcedure,
+		svc.ComputeAverage,
+		connect.WithSchema(calculatorServiceMethods.ByName("ComputeAverage")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceFindMaximumHandler := connect.NewBidiStreamHandler(
+		CalculatorServiceFindMaximumProcedure,
+		svc.FindMaximum,
+		connect.WithSchema(calculatorServiceMethods.ByName("FindMaximum")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceSquareRootHandler := connect.NewUnaryHandler(
+		CalculatorServiceSquareRootProcedure,
+		svc.SquareRoot,
+		connect.WithSchema(calculatorServiceMethods.ByName("SquareRoot")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceBigCalculateHandler := connect.NewUnaryHandler(
+		CalculatorServiceBigCalculateProcedure,
+		svc.BigCalculate,
+		connect.WithSchema(calculatorServiceMethods.ByName("BigCalculate")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceEvaluateHandler := connect.NewUnaryHandler(
+		CalculatorServiceEvaluateProcedure,
+		svc.Evaluate,
+		connect.WithSchema(calculatorServiceMethods.ByName("Evaluate")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceComputeStatisticsHandler := connect.NewClientStreamHandler(
+		CalculatorServiceComputeStatisticsProcedure,
+		svc.ComputeStatistics,
+		connect.WithSchema(calculatorServiceMethods.ByName("ComputeStatistics")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceRunningStatisticsHandler := connect.NewBidiStreamHandler(
+		CalculatorServiceRunningStatisticsProcedure,
+		svc.RunningStatistics,
+		connect.WithSchema(calculatorServiceMethods.ByName("RunningStatistics")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceAggregateHandler := connect.NewBidiStreamHandler(
+		CalculatorServiceAggregateProcedure,
+		svc.Aggregate,
+		connect.WithSchema(calculatorServiceMethods.ByName("Aggregate")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceComputeMatrixHandler := connect.NewBidiStreamHandler(
+		CalculatorServiceComputeMatrixProcedure,
+		svc.ComputeMatrix,
+		connect.WithSchema(calculatorServiceMethods.ByName("ComputeMatrix")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceConvertHandler := connect.NewUnaryHandler(
+		CalculatorServiceConvertProcedure,
+		svc.Convert,
+		connect.WithSchema(calculatorServiceMethods.ByName("Convert")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceSubmitJobHandler := connect.NewUnaryHandler(
+		CalculatorServiceSubmitJobProcedure,
+		svc.SubmitJob,
+		connect.WithSchema(calculatorServiceMethods.ByName("SubmitJob")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceGetJobHandler := connect.NewUnaryHandler(
+		CalculatorServiceGetJobProcedure,
+		svc.GetJob,
+		connect.WithSchema(calculatorServiceMethods.ByName("GetJob")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceCancelJobHandler := connect.NewUnaryHandler(
+		CalculatorServiceCancelJobProcedure,
+		svc.CancelJob,
+		connect.WithSchema(calculatorServiceMethods.ByName("CancelJob")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceWaitJobHandler := connect.NewUnaryHandler(
+		CalculatorServiceWaitJobProcedure,
+		svc.WaitJob,
+		connect.WithSchema(calculatorServiceMethods.ByName("WaitJob")),
+		connect.WithHandlerOptions(opts...),
+	)
+	calculatorServiceListJobsHandler := connect.NewUnaryHandler(
+		CalculatorServiceListJobsProcedure,
+		svc.ListJobs,
+		connect.WithSchema(calculatorServiceMethods.ByName("ListJobs")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/calculator.CalculatorService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case CalculatorServiceSumProcedure:
+			calculatorServiceSumHandler.ServeHTTP(w, r)
+		case CalculatorServicePrimeNumberDecompositionProcedure:
+			calculatorServicePrimeNumberDecompositionHandler.ServeHTTP(w, r)
+		case CalculatorServiceComputeAverageProcedure:
+			calculatorServiceComputeAverageHandler.ServeHTTP(w, r)
+		case CalculatorServiceFindMaximumProcedure:
+			calculatorServiceFindMaximumHandler.ServeHTTP(w, r)
+		case CalculatorServiceSquareRootProcedure:
+			calculatorServiceSquareRootHandler.ServeHTTP(w, r)
+		case CalculatorServiceBigCalculateProcedure:
+			calculatorServiceBigCalculateHandler.ServeHTTP(w, r)
+		case CalculatorServiceEvaluateProcedure:
+			calculatorServiceEvaluateHandler.ServeHTTP(w, r)
+		case CalculatorServiceComputeStatisticsProcedure:
+			calculatorServiceComputeStatisticsHandler.ServeHTTP(w, r)
+		case CalculatorServiceRunningStatisticsProcedure:
+			calculatorServiceRunningStatisticsHandler.ServeHTTP(w, r)
+		case CalculatorServiceAggregateProcedure:
+			calculatorServiceAggregateHandler.ServeHTTP(w, r)
+		case CalculatorServiceComputeMatrixProcedure:
+			calculatorServiceComputeMatrixHandler.ServeHTTP(w, r)
+		case CalculatorServiceConvertProcedure:
+			calculatorServiceConvertHandler.ServeHTTP(w, r)
+		case CalculatorServiceSubmitJobProcedure:
+			calculatorServiceSubmitJobHandler.ServeHTTP(w, r)
+		case CalculatorServiceGetJobProcedure:
+			calculatorServiceGetJobHandler.ServeHTTP(w, r)
+		case CalculatorServiceCancelJobProcedure:
+			calculatorServiceCancelJobHandler.ServeHTTP(w, r)
+		case CalculatorServiceWaitJobProcedure:
+			calculatorServiceWaitJobHandler.ServeHTTP(w, r)
+		case CalculatorServiceListJobsProcedure:
+			calculatorServiceListJobsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedCalculatorServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedCalculatorServiceHandler struct{}
+
+func (UnimplementedCalculatorServiceHandler) Sum(context.Context, *connect.Request[calculatorpb.SumRequest]) (*connect.Response[calculatorpb.SumResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.Sum is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) PrimeNumberDecomposition(context.Context, *connect.Request[calculatorpb.PrimeNumberDecompositionRequest], *connect.ServerStream[calculatorpb.PrimeNumberDecompositionResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.PrimeNumberDecomposition is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) ComputeAverage(context.Context, *connect.ClientStream[calculatorpb.ComputeAverageRequest]) (*connect.Response[calculatorpb.ComputeAverageResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.ComputeAverage is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) FindMaximum(context.Context, *connect.BidiStream[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.FindMaximum is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) SquareRoot(context.Context, *connect.Request[calculatorpb.SquareRootRequest]) (*connect.Response[calculatorpb.SquareRootResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.SquareRoot is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) BigCalculate(context.Context, *connect.Request[calculatorpb.BigCalculateRequest]) (*connect.Response[calculatorpb.BigCalculateResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.BigCalculate is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) Evaluate(context.Context, *connect.Request[calculatorpb.EvaluateRequest]) (*connect.Response[calculatorpb.EvaluateResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.Evaluate is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) ComputeStatistics(context.Context, *connect.ClientStream[calculatorpb.ComputeStatisticsRequest]) (*connect.Response[calculatorpb.ComputeStatisticsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.ComputeStatistics is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) RunningStatistics(context.Context, *connect.BidiStream[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.RunningStatistics is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) Aggregate(context.Context, *connect.BidiStream[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.Aggregate is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) ComputeMatrix(context.Context, *connect.BidiStream[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.ComputeMatrix is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) Convert(context.Context, *connect.Request[calculatorpb.ConvertRequest]) (*connect.Response[calculatorpb.ConvertResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.Convert is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) SubmitJob(context.Context, *connect.Request[calculatorpb.SubmitJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.SubmitJob is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) GetJob(context.Context, *connect.Request[calculatorpb.GetJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.GetJob is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) CancelJob(context.Context, *connect.Request[calculatorpb.CancelJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.CancelJob is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) WaitJob(context.Context, *connect.Request[calculatorpb.WaitJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.WaitJob is not implemented"))
+}
+
+func (UnimplementedCalculatorServiceHandler) ListJobs(context.Context, *connect.Request[calculatorpb.ListJobsRequest]) (*connect.Response[calculatorpb.ListJobsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("calculator.CalculatorService.ListJobs is not implemented"))
+}
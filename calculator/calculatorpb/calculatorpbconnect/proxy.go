@@ -0,0 +1,104 @@
+package calculatorpbconnect
+
+import (
+	"context"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/httpmux"
+	"google.golang.org/grpc"
+)
+
+// NewCalculatorServiceProxyHandler returns the path and the Connect handler
+// of the CalculatorService, proxying the calls to the gRPC server of cc.
+// It is a httpmux.ConnectService.
+func NewCalculatorServiceProxyHandler(cc grpc.ClientConnInterface) (string, http.Handler) {
+	return NewCalculatorServiceHandler(&calculatorServiceProxy{client: calculatorpb.NewCalculatorServiceClient(cc)})
+}
+
+type calculatorServiceProxy struct {
+	client calculatorpb.CalculatorServiceClient
+}
+
+func (p *calculatorServiceProxy) Sum(ctx context.Context, req *connect.Request[calculatorpb.SumRequest]) (*connect.Response[calculatorpb.SumResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.Sum)
+}
+
+func (p *calculatorServiceProxy) PrimeNumberDecomposition(ctx context.Context, req *connect.Request[calculatorpb.PrimeNumberDecompositionRequest], stream *connect.ServerStream[calculatorpb.PrimeNumberDecompositionResponse]) error {
+	return httpmux.ProxyServerStream(ctx, req, stream, func(ctx context.Context, req *calculatorpb.PrimeNumberDecompositionRequest) (httpmux.Receiver[calculatorpb.PrimeNumberDecompositionResponse], error) {
+		return p.client.PrimeNumberDecomposition(ctx, req)
+	})
+}
+
+func (p *calculatorServiceProxy) ComputeAverage(ctx context.Context, stream *connect.ClientStream[calculatorpb.ComputeAverageRequest]) (*connect.Response[calculatorpb.ComputeAverageResponse], error) {
+	return httpmux.ProxyClientStream(ctx, stream, func(ctx context.Context) (httpmux.ClientStreamer[calculatorpb.ComputeAverageRequest, calculatorpb.ComputeAverageResponse], error) {
+		return p.client.ComputeAverage(ctx)
+	})
+}
+
+func (p *calculatorServiceProxy) FindMaximum(ctx context.Context, stream *connect.BidiStream[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse]) error {
+	return httpmux.ProxyBidiStream(ctx, stream, func(ctx context.Context) (httpmux.BidiStreamer[calculatorpb.FindMaximumRequest, calculatorpb.FindMaximumResponse], error) {
+		return p.client.FindMaximum(ctx)
+	})
+}
+
+func (p *calculatorServiceProxy) SquareRoot(ctx context.Context, req *connect.Request[calculatorpb.SquareRootRequest]) (*connect.Response[calculatorpb.SquareRootResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.SquareRoot)
+}
+
+func (p *calculatorServiceProxy) BigCalculate(ctx context.Context, req *connect.Request[calculatorpb.BigCalculateRequest]) (*connect.Response[calculatorpb.BigCalculateResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.BigCalculate)
+}
+
+func (p *calculatorServiceProxy) Evaluate(ctx context.Context, req *connect.Request[calculatorpb.EvaluateRequest]) (*connect.Response[calculatorpb.EvaluateResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.Evaluate)
+}
+
+func (p *calculatorServiceProxy) ComputeStatistics(ctx context.Context, stream *connect.ClientStream[calculatorpb.ComputeStatisticsRequest]) (*connect.Response[calculatorpb.ComputeStatisticsResponse], error) {
+	return httpmux.ProxyClientStream(ctx, stream, func(ctx context.Context) (httpmux.ClientStreamer[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse], error) {
+		return p.client.ComputeStatistics(ctx)
+	})
+}
+
+func (p *calculatorServiceProxy) RunningStatistics(ctx context.Context, stream *connect.BidiStream[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse]) error {
+	return httpmux.ProxyBidiStream(ctx, stream, func(ctx context.Context) (httpmux.BidiStreamer[calculatorpb.ComputeStatisticsRequest, calculatorpb.ComputeStatisticsResponse], error) {
+		return p.client.RunningStatistics(ctx)
+	})
+}
+
+func (p *calculatorServiceProxy) Aggregate(ctx context.Context, stream *connect.BidiStream[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse]) error {
+	return httpmux.ProxyBidiStream(ctx, stream, func(ctx context.Context) (httpmux.BidiStreamer[calculatorpb.AggregateRequest, calculatorpb.AggregateResponse], error) {
+		return p.client.Aggregate(ctx)
+	})
+}
+
+func (p *calculatorServiceProxy) ComputeMatrix(ctx context.Context, stream *connect.BidiStream[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse]) error {
+	return httpmux.ProxyBidiStream(ctx, stream, func(ctx context.Context) (httpmux.BidiStreamer[calculatorpb.MatrixRequest, calculatorpb.MatrixResponse], error) {
+		return p.client.ComputeMatrix(ctx)
+	})
+}
+
+func (p *calculatorServiceProxy) Convert(ctx context.Context, req *connect.Request[calculatorpb.ConvertRequest]) (*connect.Response[calculatorpb.ConvertResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.Convert)
+}
+
+func (p *calculatorServiceProxy) SubmitJob(ctx context.Context, req *connect.Request[calculatorpb.SubmitJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.SubmitJob)
+}
+
+func (p *calculatorServiceProxy) GetJob(ctx context.Context, req *connect.Request[calculatorpb.GetJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.GetJob)
+}
+
+func (p *calculatorServiceProxy) CancelJob(ctx context.Context, req *connect.Request[calculatorpb.CancelJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.CancelJob)
+}
+
+func (p *calculatorServiceProxy) WaitJob(ctx context.Context, req *connect.Request[calculatorpb.WaitJobRequest]) (*connect.Response[calculatorpb.Job], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.WaitJob)
+}
+
+func (p *calculatorServiceProxy) ListJobs(ctx context.Context, req *connect.Request[calculatorpb.ListJobsRequest]) (*connect.Response[calculatorpb.ListJobsResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.ListJobs)
+}
@@ -0,0 +1,69 @@
+package calculatorpb
+
+import "github.com/pjserol/tuto-grpc-go/validation"
+
+// decimalPattern matches the decimal strings of the BigCalculateRequest.
+const decimalPattern = `^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`
+
+// maxDecimalLen bounds the length of the decimal strings.
+const maxDecimalLen = 1000
+
+// maxWindowSize bounds the size of the aggregation windows, a day in
+// milliseconds.
+const maxWindowSize = 24 * 60 * 60 * 1000
+
+// maxMatrixColumns bounds the length of the rows of the matrices.
+const maxMatrixColumns = 10000
+
+func init() {
+	validation.Register(&PrimeNumberDecompositionRequest{}, validation.Rules{
+		"number":     {Min: validation.Bound(0)},
+		"big_number": {MaxLen: 200, Pattern: `^[0-9]+$`},
+	})
+	validation.Register(&SquareRootRequest{}, validation.Rules{
+		"number": {Min: validation.Bound(0)},
+	})
+	validation.Register(&BigCalculateRequest{}, validation.Rules{
+		"operation":     {Required: true},
+		"first_number":  {Required: true, MaxLen: maxDecimalLen, Pattern: decimalPattern},
+		"second_number": {MaxLen: maxDecimalLen, Pattern: decimalPattern},
+		"precision":     {Min: validation.Bound(0), Max: validation.Bound(1000)},
+	})
+	validation.Register(&ComputeStatisticsRequest{}, validation.Rules{
+		"percentiles": {MaxLen: 20},
+		"emit_every":  {Min: validation.Bound(0)},
+	})
+	validation.Register(&AggregateRequest{}, validation.Rules{
+		"window_size": {Min: validation.Bound(0), Max: validation.Bound(maxWindowSize)},
+		"slide":       {Min: validation.Bound(0), Max: validation.Bound(maxWindowSize)},
+	})
+	validation.Register(&MatrixRequest{}, validation.Rules{
+		"a.values": {MaxLen: maxMatrixColumns},
+		"b.values": {MaxLen: maxMatrixColumns},
+	})
+	validation.Register(&ConvertRequest{}, validation.Rules{
+		"from": {Required: true, MaxLen: 20},
+		"to":   {Required: true, MaxLen: 20},
+	})
+	validation.Register(&SubmitJobRequest{}, validation.Rules{
+		"prime_number_decomposition.number":     {Min: validation.Bound(0)},
+		"prime_number_decomposition.big_number": {MaxLen: 200, Pattern: `^[0-9]+$`},
+	})
+	validation.Register(&GetJobRequest{}, validation.Rules{
+		"id": {Required: true, MaxLen: 100},
+	})
+	validation.Register(&CancelJobRequest{}, validation.Rules{
+		"id": {Required: true, MaxLen: 100},
+	})
+	validation.Register(&WaitJobRequest{}, validation.Rules{
+		"id": {Required: true, MaxLen: 100},
+	})
+	validation.Register(&ListJobsRequest{}, validation.Rules{
+		"page_size":  {Min: validation.Bound(0), Max: validation.Bound(1000)},
+		"page_token": {MaxLen: 200},
+	})
+	validation.Register(&EvaluateRequest{}, validation.Rules{
+		"expression": {Required: true, MaxLen: 1000},
+		"variables":  {MaxLen: 100},
+	})
+}
@@ -0,0 +1,319 @@
+package calculatorserver
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/status"
+)
+
+// maxPanes bounds the number of panes of a window: window_size divided by
+// the greatest common divisor of window_size and slide.
+const maxPanes = 10000
+
+// aggregate is the aggregation of the values of a pane or a window.
+type aggregate struct {
+	count    int64
+	sum      float64
+	min, max float64
+}
+
+func (a *aggregate) add(v float64) {
+	a.merge(aggregate{count: 1, sum: v, min: v, max: v})
+}
+
+func (a *aggregate) merge(b aggregate) {
+	if b.count == 0 {
+		return
+	}
+	if a.count == 0 || b.min < a.min {
+		a.min = b.min
+	}
+	if a.count == 0 || b.max > a.max {
+		a.max = b.max
+	}
+	a.count += b.count
+	a.sum += b.sum
+}
+
+func (a aggregate) result(aggregation calculatorpb.Aggregation) float64 {
+	switch aggregation {
+	case calculatorpb.Aggregation_MAX:
+		return a.max
+	case calculatorpb.Aggregation_MIN:
+		return a.min
+	case calculatorpb.Aggregation_SUM:
+		return a.sum
+	default:
+		return a.sum / float64(a.count)
+	}
+}
+
+// windows aggregates the values by window. The positions of the values,
+// from 0, are split into panes of the greatest common divisor of the size
+// and the slide of the windows: a window is made of whole panes, so each
+// value is added once, whatever the number of windows it belongs to, and
+// the memory used only depends on the number of panes of a window.
+type windows struct {
+	aggregation       calculatorpb.Aggregation
+	size, slide, pane int64
+
+	// panes[i] is the pane first+i
+	panes []aggregate
+	first int64
+	// next is the index of the next window to close, starting at
+	// next*slide
+	next int64
+	// end is the position after the last value
+	end int64
+}
+
+// newWindows reads the windows from the first request.
+func newWindows(req *calculatorpb.AggregateRequest) (*windows, error) {
+	if req.GetAggregation() == calculatorpb.Aggregation_AGGREGATION_UNSPECIFIED {
+		return nil, grpcerr.InvalidArgument("aggregation", "Must be set in the first request")
+	}
+	size := req.GetWindowSize()
+	if size <= 0 {
+		return nil, grpcerr.InvalidArgument("window_size", "Must be set in the first request")
+	}
+	slide := req.GetSlide()
+	if slide == 0 {
+		slide = size
+	}
+
+	pane := gcd(size, slide)
+	if size/pane > maxPanes {
+		return nil, grpcerr.InvalidArgument("slide", fmt.Sprintf("window_size / gcd(window_size, slide) must be at most %d", maxPanes))
+	}
+
+	return &windows{
+		aggregation: req.GetAggregation(),
+		size:        size,
+		slide:       slide,
+		pane:        pane,
+	}, nil
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// add adds v at pos, which is greater than or equal to the positions of
+// the previous values.
+func (w *windows) add(pos int64, v float64) {
+	w.end = pos + 1
+
+	p := pos / w.pane
+	if p < w.first {
+		// between two windows, when the slide is larger than the size
+		return
+	}
+	for w.first+int64(len(w.panes)) <= p {
+		w.panes = append(w.panes, aggregate{})
+	}
+	w.panes[p-w.first].add(v)
+}
+
+// nextEnd is the position at which the next window closes.
+func (w *windows) nextEnd() int64 {
+	return w.next*w.slide + w.size
+}
+
+// close returns the results of the windows ending at or before pos and,
+// when final, of the windows that started before the last value.
+func (w *windows) close(pos int64, final bool) []*calculatorpb.AggregateResponse {
+	var results []*calculatorpb.AggregateResponse
+
+	for {
+		if len(w.panes) == 0 {
+			// no value to aggregate: skip the windows already ended
+			if pos >= w.size {
+				if next := (pos-w.size)/w.slide + 1; next > w.next {
+					w.next = next
+					w.drop()
+				}
+			}
+			return results
+		}
+
+		start := w.next * w.slide
+		end := start + w.size
+		if end > pos && !(final && start < w.end) {
+			return results
+		}
+
+		var a aggregate
+		for p := start / w.pane; p < end/w.pane; p++ {
+			if i := p - w.first; i >= 0 && i < int64(len(w.panes)) {
+				a.merge(w.panes[i])
+			}
+		}
+		if a.count > 0 {
+			results = append(results, &calculatorpb.AggregateResponse{
+				Result:      a.result(w.aggregation),
+				Count:       a.count,
+				WindowStart: start,
+				WindowEnd:   end,
+				Partial:     end > pos,
+			})
+		}
+
+		w.next++
+		w.drop()
+	}
+}
+
+// drop removes the panes before the next window.
+func (w *windows) drop() {
+	first := w.next * w.slide / w.pane
+	n := first - w.first
+	if n <= 0 {
+		return
+	}
+
+	if n >= int64(len(w.panes)) {
+		w.panes = w.panes[:0]
+	} else {
+		w.panes = append(w.panes[:0], w.panes[n:]...)
+	}
+	w.first = first
+}
+
+// sendAggregates sends the results, failing with OutOfRange on the first
+// one whose sum overflows.
+func sendAggregates(stream calculatorpb.CalculatorService_AggregateServer, results []*calculatorpb.AggregateResponse) error {
+	for _, res := range results {
+		if !finite(res.GetResult()) {
+			return grpcerr.OutOfRange("value", fmt.Sprintf("The sum of the window [%d, %d) overflows a double", res.GetWindowStart(), res.GetWindowEnd()))
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*Server) Aggregate(stream calculatorpb.CalculatorService_AggregateServer) error {
+	req, err := stream.Recv()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	w, err := newWindows(req)
+	if err != nil {
+		return err
+	}
+
+	if req.GetUnit() == calculatorpb.WindowUnit_MILLISECONDS {
+		return aggregateByTime(stream, w, req.GetValue())
+	}
+	return aggregateByCount(stream, w, req.GetValue())
+}
+
+// aggregateByCount closes the windows as soon as their last value is
+// received.
+func aggregateByCount(stream calculatorpb.CalculatorService_AggregateServer, w *windows, value float64) error {
+	for pos := int64(0); ; pos++ {
+		if err := checkValue(value); err != nil {
+			return err
+		}
+		w.add(pos, value)
+		if err := sendAggregates(stream, w.close(pos+1, false)); err != nil {
+			return err
+		}
+
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return sendAggregates(stream, w.close(pos+1, true))
+		} else if err != nil {
+			return err
+		}
+		value = req.GetValue()
+	}
+}
+
+// aggregateByTime closes the windows when their end time is reached, even
+// if no value is received.
+func aggregateByTime(stream calculatorpb.CalculatorService_AggregateServer, w *windows, value float64) error {
+	ctx := stream.Context()
+	start := time.Now()
+	elapsed := func() int64 {
+		return time.Since(start).Milliseconds()
+	}
+
+	reqs := make(chan *calculatorpb.AggregateRequest)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case reqs <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := checkValue(value); err != nil {
+		return err
+	}
+	w.add(0, value)
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		var timeout <-chan time.Time
+		if len(w.panes) > 0 {
+			timer.Reset(time.Duration(w.nextEnd()-elapsed()) * time.Millisecond)
+			timeout = timer.C
+		}
+
+		var results []*calculatorpb.AggregateResponse
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+
+		case err := <-errc:
+			if err == io.EOF {
+				return sendAggregates(stream, w.close(elapsed(), true))
+			}
+			return err
+
+		case req := <-reqs:
+			if err := checkValue(req.GetValue()); err != nil {
+				return err
+			}
+			pos := elapsed()
+			results = w.close(pos, false)
+			w.add(pos, req.GetValue())
+
+		case <-timeout:
+			results = w.close(elapsed(), false)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if err := sendAggregates(stream, results); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package calculatorserver
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// aggregateStream is the stream of Aggregate, receiving reqs and keeping
+// the results.
+type aggregateStream struct {
+	calculatorpb.CalculatorService_AggregateServer
+	reqs    []*calculatorpb.AggregateRequest
+	results []*calculatorpb.AggregateResponse
+}
+
+func (s *aggregateStream) Recv() (*calculatorpb.AggregateRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+
+func (s *aggregateStream) Send(res *calculatorpb.AggregateResponse) error {
+	s.results = append(s.results, res)
+	return nil
+}
+
+// window is an AggregateResponse: the result, count, start, end and partial.
+type window struct {
+	result     float64
+	count      int64
+	start, end int64
+	partial    bool
+}
+
+func TestAggregate(t *testing.T) {
+	values := []float64{-5, -3, -8, -1, -7, -2, -9}
+
+	tests := []struct {
+		name     string
+		first    *calculatorpb.AggregateRequest
+		values   []float64
+		want     []window
+		wantCode codes.Code
+	}{
+		{
+			name:   "empty stream",
+			values: nil,
+		},
+		{
+			name:   "one value",
+			first:  &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_MAX, WindowSize: 3},
+			values: []float64{5},
+			want:   []window{{5, 1, 0, 3, true}},
+		},
+		{
+			name:   "window of one value",
+			first:  &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_SUM, WindowSize: 1},
+			values: []float64{1, 2},
+			want:   []window{{1, 1, 0, 1, false}, {2, 1, 1, 2, false}},
+		},
+		{
+			name:   "tumbling",
+			first:  &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_MAX, WindowSize: 3},
+			values: values,
+			want:   []window{{-3, 3, 0, 3, false}, {-1, 3, 3, 6, false}, {-9, 1, 6, 9, true}},
+		},
+		{
+			name:   "sliding",
+			first:  &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_AVG, WindowSize: 3, Slide: 1},
+			values: []float64{1, 2, 3, 4},
+			want:   []window{{2, 3, 0, 3, false}, {3, 3, 1, 4, false}, {3.5, 2, 2, 5, true}, {4, 1, 3, 6, true}},
+		},
+		{
+			name:   "hopping",
+			first:  &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_MIN, WindowSize: 2, Slide: 3},
+			values: values,
+			want:   []window{{-5, 2, 0, 2, false}, {-7, 2, 3, 5, false}, {-9, 1, 6, 8, true}},
+		},
+		{
+			name:     "sum overflow",
+			first:    &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_SUM, WindowSize: 2},
+			values:   []float64{1e308, 1e308},
+			wantCode: codes.OutOfRange,
+		},
+		{
+			name:     "average overflow",
+			first:    &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_AVG, WindowSize: 2},
+			values:   []float64{-1e308, -1e308},
+			wantCode: codes.OutOfRange,
+		},
+		{
+			name:   "max does not overflow",
+			first:  &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_MAX, WindowSize: 2},
+			values: []float64{1e308, 1e308},
+			want:   []window{{1e308, 2, 0, 2, false}},
+		},
+		{
+			name:     "NaN",
+			first:    &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_SUM, WindowSize: 2},
+			values:   []float64{1, math.NaN()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "no aggregation",
+			first:    &calculatorpb.AggregateRequest{WindowSize: 3},
+			values:   values,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "no window size",
+			first:    &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_MAX},
+			values:   values,
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "too many panes",
+			first:    &calculatorpb.AggregateRequest{Aggregation: calculatorpb.Aggregation_MAX, WindowSize: maxPanes + 1, Slide: maxPanes},
+			values:   values,
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &aggregateStream{}
+			for i, v := range tt.values {
+				req := &calculatorpb.AggregateRequest{}
+				if i == 0 {
+					req = tt.first
+				}
+				req.Value = v
+				stream.reqs = append(stream.reqs, req)
+			}
+
+			err := (&Server{}).Aggregate(stream)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			var got []window
+			for _, res := range stream.results {
+				got = append(got, window{res.GetResult(), res.GetCount(), res.GetWindowStart(), res.GetWindowEnd(), res.GetPartial()})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("windows = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("windows = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package calculatorserver
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+)
+
+const (
+	// defaultPrecision is the maximum number of decimals of the results
+	// when the request does not set it.
+	defaultPrecision = 20
+	// maxExponent bounds the exponent of the decimal strings, e.g. 1e1000.
+	maxExponent = 1000
+	// maxPowExponent bounds the exponent of POW.
+	maxPowExponent = 10000
+	// maxResultBits bounds the size of the numerator and denominator of
+	// the results, about 300,000 digits.
+	maxResultBits = 1 << 20
+)
+
+var bigTen = big.NewInt(10)
+
+// bigCalculate computes the operation of req, and formats the result.
+func bigCalculate(req *calculatorpb.BigCalculateRequest) (*calculatorpb.BigCalculateResponse, error) {
+	precision := int(req.GetPrecision())
+	if precision == 0 {
+		precision = defaultPrecision
+	}
+
+	x, err := parseDecimal("first_number", req.GetFirstNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	var y *big.Rat
+	if req.GetOperation() != calculatorpb.BigOperation_SQRT {
+		if req.GetSecondNumber() == "" {
+			return nil, grpcerr.InvalidArgument("second_number", "must not be empty")
+		}
+		if y, err = parseDecimal("second_number", req.GetSecondNumber()); err != nil {
+			return nil, err
+		}
+	}
+
+	var z *big.Rat
+	switch req.GetOperation() {
+	case calculatorpb.BigOperation_ADD:
+		z = new(big.Rat).Add(x, y)
+	case calculatorpb.BigOperation_SUBTRACT:
+		z = new(big.Rat).Sub(x, y)
+	case calculatorpb.BigOperation_MULTIPLY:
+		z = new(big.Rat).Mul(x, y)
+	case calculatorpb.BigOperation_DIVIDE:
+		if y.Sign() == 0 {
+			return nil, grpcerr.InvalidArgument("second_number", "Division by zero")
+		}
+		z = new(big.Rat).Quo(x, y)
+	case calculatorpb.BigOperation_MOD:
+		if y.Sign() == 0 {
+			return nil, grpcerr.InvalidArgument("second_number", "Division by zero")
+		}
+		z = mod(x, y)
+	case calculatorpb.BigOperation_POW:
+		if z, err = pow(x, y); err != nil {
+			return nil, err
+		}
+	case calculatorpb.BigOperation_SQRT:
+		if x.Sign() < 0 {
+			return nil, grpcerr.InvalidArgument("first_number", fmt.Sprintf("Received a negative number: %s", req.GetFirstNumber()))
+		}
+		root, ok := exactSqrt(x)
+		if !ok {
+			// one more decimal, so that formatDecimal rounds correctly
+			return &calculatorpb.BigCalculateResponse{
+				Result: trimZeros(sqrt(x, precision+1).FloatString(precision)),
+			}, nil
+		}
+		z = root
+	default:
+		return nil, grpcerr.InvalidArgument("operation", fmt.Sprintf("Unknown operation %v", req.GetOperation()))
+	}
+
+	if z.Num().BitLen() > maxResultBits || z.Denom().BitLen() > maxResultBits {
+		return nil, grpcerr.OutOfRange("result", "The result is too large")
+	}
+
+	result, exact := formatDecimal(z, precision)
+	return &calculatorpb.BigCalculateResponse{
+		Result: result,
+		Exact:  exact,
+	}, nil
+}
+
+// bigCalculateKey returns the key of req in the cache of the results: the
+// operation, the precision and the numbers in lowest terms, so that "0.5"
+// and "5e-1" share their result. It is false for the invalid requests.
+func bigCalculateKey(req *calculatorpb.BigCalculateRequest) (string, bool) {
+	precision := req.GetPrecision()
+	if precision == 0 {
+		precision = defaultPrecision
+	}
+
+	x, err := parseDecimal("first_number", req.GetFirstNumber())
+	if err != nil {
+		return "", false
+	}
+	key := fmt.Sprintf("%v|%d|%s", req.GetOperation(), precision, x.RatString())
+
+	if req.GetOperation() != calculatorpb.BigOperation_SQRT {
+		y, err := parseDecimal("second_number", req.GetSecondNumber())
+		if err != nil {
+			return "", false
+		}
+		key += "|" + y.RatString()
+	}
+	return key, true
+}
+
+// parseDecimal parses s, a decimal string matching the pattern of the
+// validation rules, with an exponent of at most maxExponent.
+func parseDecimal(field string, s string) (*big.Rat, error) {
+	mantissa, exponent := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > maxExponent || e < -maxExponent {
+			return nil, grpcerr.InvalidArgument(field, fmt.Sprintf("The exponent must be between %d and %d", -maxExponent, maxExponent))
+		}
+		exponent = e
+	}
+
+	x, ok := new(big.Rat).SetString(mantissa)
+	if !ok {
+		return nil, grpcerr.InvalidArgument(field, "Cannot parse number")
+	}
+
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(bigTen, big.NewInt(int64(abs(exponent))), nil))
+	if exponent >= 0 {
+		return x.Mul(x, scale), nil
+	}
+	return x.Quo(x, scale), nil
+}
+
+// mod returns the remainder of the truncated division of x by y, with the
+// sign of x.
+func mod(x *big.Rat, y *big.Rat) *big.Rat {
+	q := new(big.Rat).Quo(x, y)
+	// Quo truncates towards zero
+	trunc := new(big.Int).Quo(q.Num(), q.Denom())
+	return new(big.Rat).Sub(x, new(big.Rat).Mul(y, new(big.Rat).SetInt(trunc)))
+}
+
+// pow returns x to the power of y, an integer.
+func pow(x *big.Rat, y *big.Rat) (*big.Rat, error) {
+	if !y.IsInt() {
+		return nil, grpcerr.InvalidArgument("second_number", "The exponent must be an integer")
+	}
+	if y.Num().CmpAbs(big.NewInt(maxPowExponent)) > 0 {
+		return nil, grpcerr.OutOfRange("second_number", fmt.Sprintf("The exponent must be between %d and %d", -maxPowExponent, maxPowExponent))
+	}
+
+	n := y.Num().Int64()
+	if n < 0 && x.Sign() == 0 {
+		return nil, grpcerr.InvalidArgument("first_number", "Division by zero")
+	}
+
+	e := big.NewInt(int64(abs(int(n))))
+	// the size of the result is known before computing it
+	if int64(x.Num().BitLen())*e.Int64() > maxResultBits || int64(x.Denom().BitLen())*e.Int64() > maxResultBits {
+		return nil, grpcerr.OutOfRange("result", "The result is too large")
+	}
+
+	z := new(big.Rat).SetFrac(
+		new(big.Int).Exp(x.Num(), e, nil),
+		new(big.Int).Exp(x.Denom(), e, nil),
+	)
+	if n < 0 {
+		z.Inv(z)
+	}
+	return z, nil
+}
+
+// sqrt returns the square root of x >= 0, truncated to precision decimals.
+func sqrt(x *big.Rat, precision int) *big.Rat {
+	// floor(sqrt(x * 10^(2 * precision))) / 10^precision
+	scale := new(big.Int).Exp(bigTen, big.NewInt(int64(precision)), nil)
+	n := new(big.Int).Mul(x.Num(), new(big.Int).Mul(scale, scale))
+	n.Quo(n, x.Denom())
+	return new(big.Rat).SetFrac(n.Sqrt(n), scale)
+}
+
+// exactSqrt returns the square root of x >= 0 when it is an exact
+// decimal, i.e. x is the square of a decimal.
+func exactSqrt(x *big.Rat) (*big.Rat, bool) {
+	num, denom := new(big.Int).Sqrt(x.Num()), new(big.Int).Sqrt(x.Denom())
+	if new(big.Int).Mul(num, num).Cmp(x.Num()) != 0 || new(big.Int).Mul(denom, denom).Cmp(x.Denom()) != 0 {
+		return nil, false
+	}
+
+	root := new(big.Rat).SetFrac(num, denom)
+	if _, exact := decimals(root); !exact {
+		return nil, false
+	}
+	return root, true
+}
+
+// decimals returns the number of decimals of x, and whether x is an exact
+// decimal: its denominator is 2^a * 5^b, and it has max(a, b) decimals.
+func decimals(x *big.Rat) (int, bool) {
+	d := new(big.Int).Set(x.Denom())
+	twos := int(d.TrailingZeroBits())
+	d.Rsh(d, uint(twos))
+
+	// d must be 5^fives, 5^n having about n * log2(5) bits
+	fives := int(float64(d.BitLen()) / 2.321928094887362)
+	for _, n := range []int{fives - 1, fives, fives + 1} {
+		if n >= 0 && new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(n)), nil).Cmp(d) == 0 {
+			if twos > n {
+				return twos, true
+			}
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// formatDecimal formats x with all its decimals when it is an exact
+// decimal with at most precision decimals, rounded to precision decimals
+// otherwise, without the trailing zeros. It is false when x was rounded.
+func formatDecimal(x *big.Rat, precision int) (string, bool) {
+	n, exact := decimals(x)
+	if !exact || n > precision {
+		return trimZeros(x.FloatString(precision)), false
+	}
+
+	return trimZeros(x.FloatString(n)), true
+}
+
+// trimZeros removes the trailing zeros of the decimals of s.
+func trimZeros(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
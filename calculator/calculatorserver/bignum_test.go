@@ -0,0 +1,112 @@
+package calculatorserver
+
+import (
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBigCalculate(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *calculatorpb.BigCalculateRequest
+		want      string
+		wantExact bool
+		wantCode  codes.Code
+	}{
+		{
+			name:      "add",
+			req:       &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_ADD, FirstNumber: "0.1", SecondNumber: "0.2"},
+			want:      "0.3",
+			wantExact: true,
+		},
+		{
+			name:      "multiply large numbers",
+			req:       &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_MULTIPLY, FirstNumber: "123456789012345678901234567890", SecondNumber: "1e10"},
+			want:      "1234567890123456789012345678900000000000",
+			wantExact: true,
+		},
+		{
+			name: "divide",
+			req:  &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_DIVIDE, FirstNumber: "2", SecondNumber: "3", Precision: 5},
+			want: "0.66667",
+		},
+		{
+			name:      "exact division",
+			req:       &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_DIVIDE, FirstNumber: "1", SecondNumber: "8"},
+			want:      "0.125",
+			wantExact: true,
+		},
+		{
+			name: "exact division beyond the precision",
+			req:  &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_DIVIDE, FirstNumber: "1", SecondNumber: "8", Precision: 2},
+			want: "0.13",
+		},
+		{
+			name: "pow with a negative exponent",
+			req:  &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_POW, FirstNumber: "2", SecondNumber: "-10000"},
+			want: "0",
+		},
+		{
+			name:      "pow with a negative exponent within the precision",
+			req:       &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_POW, FirstNumber: "2", SecondNumber: "-10", Precision: 10},
+			want:      "0.0009765625",
+			wantExact: true,
+		},
+		{
+			name:      "pow with a small negative exponent",
+			req:       &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_POW, FirstNumber: "2", SecondNumber: "-3"},
+			want:      "0.125",
+			wantExact: true,
+		},
+		{
+			name: "negative rounded half away from zero",
+			req:  &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_DIVIDE, FirstNumber: "-1", SecondNumber: "8", Precision: 2},
+			want: "-0.13",
+		},
+		{
+			name:      "exact square root",
+			req:       &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_SQRT, FirstNumber: "0.25"},
+			want:      "0.5",
+			wantExact: true,
+		},
+		{
+			name: "square root",
+			req:  &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_SQRT, FirstNumber: "2", Precision: 10},
+			want: "1.4142135624",
+		},
+		{
+			name:      "mod",
+			req:       &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_MOD, FirstNumber: "-7", SecondNumber: "3"},
+			want:      "-1",
+			wantExact: true,
+		},
+		{
+			name:     "division by zero",
+			req:      &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_DIVIDE, FirstNumber: "1", SecondNumber: "0"},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "negative square root",
+			req:      &calculatorpb.BigCalculateRequest{Operation: calculatorpb.BigOperation_SQRT, FirstNumber: "-1"},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := bigCalculate(tt.req)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if res.GetResult() != tt.want || res.GetExact() != tt.wantExact {
+				t.Errorf("result = %.50s (exact: %v), want %s (exact: %v)", res.GetResult(), res.GetExact(), tt.want, tt.wantExact)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package calculatorserver
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb/calculatorpbconnect"
+)
+
+// Metrics of the caches by full method name, published with expvar.
+var (
+	// CacheHits counts the results read from the caches.
+	CacheHits = expvar.NewMap("calculator_cache_hits_total")
+	// CacheMisses counts the results computed.
+	CacheMisses = expvar.NewMap("calculator_cache_misses_total")
+	// CacheShared counts the calls that waited for the result of an
+	// identical call in progress instead of computing it.
+	CacheShared = expvar.NewMap("calculator_cache_shared_total")
+)
+
+// MethodCache is a LRU cache of the Size most recently used results of a
+// method, each kept for at most TTLSeconds. A zero Size disables the cache,
+// a zero TTLSeconds keeps the results until they are evicted.
+type MethodCache struct {
+	Size       int     `json:"size"`
+	TTLSeconds float64 `json:"ttl_seconds"`
+}
+
+// CacheConfig configures the caches of the results of the expensive
+// methods.
+type CacheConfig struct {
+	// Methods are the caches by full method name, e.g.
+	// /calculator.CalculatorService/BigCalculate. The results of the
+	// methods not listed are not cached.
+	Methods map[string]MethodCache `json:"methods"`
+}
+
+// DefaultCacheConfig is used when no configuration file is given.
+var DefaultCacheConfig = CacheConfig{
+	Methods: map[string]MethodCache{
+		calculatorpbconnect.CalculatorServicePrimeNumberDecompositionProcedure: {Size: 1000, TTLSeconds: 3600},
+		calculatorpbconnect.CalculatorServiceBigCalculateProcedure:             {Size: 1000, TTLSeconds: 600},
+	},
+}
+
+// LoadCacheConfig reads a JSON CacheConfig from path.
+func LoadCacheConfig(path string) (CacheConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return CacheConfig{}, err
+	}
+
+	var cfg CacheConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return CacheConfig{}, fmt.Errorf("Cannot parse cache config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// errCallPanicked is the result of a computation that panicked: the calls
+// waiting for it compute the result themselves.
+var errCallPanicked = errors.New("calculatorserver: cached computation panicked")
+
+// resultCache memoizes the results of a method by normalized request. The
+// identical calls received while a result is computed wait for it instead
+// of computing it again. A nil *resultCache computes every result.
+type resultCache[V any] struct {
+	method string
+	size   int
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	lru      *list.List // of *cacheEntry[V], the most recently used first
+	entries  map[string]*list.Element
+	inflight map[string]*inflightCall[V]
+}
+
+type cacheEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+type inflightCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// newResultCache returns the cache of method configured by cfg, nil when
+// it is disabled.
+func newResultCache[V any](method string, cfg CacheConfig) *resultCache[V] {
+	mc := cfg.Methods[method]
+	if mc.Size <= 0 {
+		return nil
+	}
+
+	return &resultCache[V]{
+		method:   method,
+		size:     mc.Size,
+		ttl:      time.Duration(mc.TTLSeconds * float64(time.Second)),
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+		inflight: map[string]*inflightCall[V]{},
+	}
+}
+
+// get returns the result of key, from the cache, or from compute run with
+// ctx. The errors are not cached.
+func (c *resultCache[V]) get(ctx context.Context, key string, compute func(ctx context.Context) (V, error)) (V, error) {
+	if c == nil {
+		return compute(ctx)
+	}
+
+	for {
+		c.mu.Lock()
+		if value, ok := c.lookup(key); ok {
+			c.mu.Unlock()
+			CacheHits.Add(c.method, 1)
+			return value, nil
+		}
+
+		call, ok := c.inflight[key]
+		if !ok {
+			call = &inflightCall[V]{done: make(chan struct{}), err: errCallPanicked}
+			c.inflight[key] = call
+			c.mu.Unlock()
+
+			CacheMisses.Add(c.method, 1)
+			defer c.complete(key, call)
+			call.value, call.err = compute(ctx)
+			return call.value, call.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		case <-call.done:
+		}
+
+		// retry when the call computing the result was canceled or
+		// panicked, rather than failing this one
+		if call.err == errCallPanicked || ((errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) && ctx.Err() == nil) {
+			continue
+		}
+		CacheShared.Add(c.method, 1)
+		return call.value, call.err
+	}
+}
+
+// lookup returns the unexpired result of key, with c.mu held.
+func (c *resultCache[V]) lookup(key string) (V, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	entry := elem.Value.(*cacheEntry[V])
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+// complete stores the result of call, and wakes up the calls waiting for
+// it.
+func (c *resultCache[V]) complete(key string, call *inflightCall[V]) {
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil {
+		c.entries[key] = c.lru.PushFront(&cacheEntry[V]{
+			key:     key,
+			value:   call.value,
+			expires: time.Now().Add(c.ttl),
+		})
+		for c.lru.Len() > c.size {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cacheEntry[V]).key)
+		}
+	}
+	c.mu.Unlock()
+
+	close(call.done)
+}
@@ -0,0 +1,159 @@
+package calculatorserver
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const testMethod = "/calculator.CalculatorService/Test"
+
+func TestResultCache(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache MethodCache
+		keys  []string
+		// computed tells whether the result of each key is computed,
+		// instead of read from the cache
+		computed []bool
+	}{
+		{
+			name:     "disabled",
+			keys:     []string{"a", "a"},
+			computed: []bool{true, true},
+		},
+		{
+			name:     "hits",
+			cache:    MethodCache{Size: 2},
+			keys:     []string{"a", "b", "a", "b"},
+			computed: []bool{true, true, false, false},
+		},
+		{
+			name:  "eviction of the least recently used",
+			cache: MethodCache{Size: 2},
+			// c evicts b, used before a, then b evicts a
+			keys:     []string{"a", "b", "a", "c", "b", "c", "a"},
+			computed: []bool{true, true, false, true, true, false, true},
+		},
+		{
+			name:     "one result",
+			cache:    MethodCache{Size: 1},
+			keys:     []string{"a", "a", "b", "a"},
+			computed: []bool{true, false, true, true},
+		},
+		{
+			name:     "expired",
+			cache:    MethodCache{Size: 2, TTLSeconds: 1e-9},
+			keys:     []string{"a", "a"},
+			computed: []bool{true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newResultCache[string](testMethod, CacheConfig{Methods: map[string]MethodCache{testMethod: tt.cache}})
+			for i, key := range tt.keys {
+				computed := false
+				value, err := c.get(context.Background(), key, func(context.Context) (string, error) {
+					computed = true
+					return key + "!", nil
+				})
+				if err != nil || value != key+"!" {
+					t.Fatalf("get(%s) = %q, %v", key, value, err)
+				}
+				if computed != tt.computed[i] {
+					t.Errorf("get(%s) #%d: computed = %v, want %v", key, i, computed, tt.computed[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResultCacheErrors(t *testing.T) {
+	c := newResultCache[int](testMethod, CacheConfig{Methods: map[string]MethodCache{testMethod: {Size: 10}}})
+	errCompute := errors.New("compute failed")
+
+	calls := 0
+	compute := func(context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errCompute
+		}
+		return calls, nil
+	}
+	if _, err := c.get(context.Background(), "a", compute); err != errCompute {
+		t.Fatalf("get() = %v, want %v", err, errCompute)
+	}
+	if v, err := c.get(context.Background(), "a", compute); err != nil || v != 2 {
+		t.Errorf("get() = %d, %v after an error, want 2, nil", v, err)
+	}
+}
+
+func TestResultCacheShared(t *testing.T) {
+	c := newResultCache[int](testMethod, CacheConfig{Methods: map[string]MethodCache{testMethod: {Size: 10}}})
+
+	var computes int32
+	release := make(chan struct{})
+	compute := func(context.Context) (int, error) {
+		atomic.AddInt32(&computes, 1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.get(context.Background(), "a", compute); err != nil || v != 42 {
+				t.Errorf("get() = %d, %v", v, err)
+			}
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	if computes != 1 {
+		t.Errorf("computed %d times, want once", computes)
+	}
+}
+
+func TestResultCacheCanceled(t *testing.T) {
+	c := newResultCache[int](testMethod, CacheConfig{Methods: map[string]MethodCache{testMethod: {Size: 10}}})
+
+	// the first call is canceled while computing the result
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := c.get(ctx, "a", func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		done <- err
+	}()
+	<-started
+
+	// the second one waits for it, then computes the result itself
+	result := make(chan int)
+	go func() {
+		v, err := c.get(context.Background(), "a", func(context.Context) (int, error) {
+			return 42, nil
+		})
+		if err != nil {
+			t.Errorf("get() = %v", err)
+		}
+		result <- v
+	}()
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("canceled get() = %v, want %v", err, context.Canceled)
+	}
+	if v := <-result; v != 42 {
+		t.Errorf("get() = %d, want 42", v)
+	}
+}
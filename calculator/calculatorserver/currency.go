@@ -0,0 +1,168 @@
+package calculatorserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+)
+
+// ratesCheckInterval is how often the rates file is checked for changes.
+const ratesCheckInterval = 10 * time.Second
+
+// ratesFile is the JSON file of the currency rates, e.g.
+//
+//	{"base": "EUR", "date": "2026-10-19", "rates": {"USD": 1.0842, "GBP": 0.8571}}
+//
+// A rate is the amount of the currency worth one unit of the base currency,
+// on the date of the file.
+type ratesFile struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+
+	date time.Time
+}
+
+// loadRates reads and checks the rates file at path.
+func loadRates(path string) (*ratesFile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates ratesFile
+	if err := json.Unmarshal(b, &rates); err != nil {
+		return nil, fmt.Errorf("Cannot parse currency rates %s: %v", path, err)
+	}
+	if rates.date, err = time.Parse("2006-01-02", rates.Date); err != nil {
+		return nil, fmt.Errorf("Invalid date %q in %s", rates.Date, path)
+	}
+	if !isCurrency(rates.Base) {
+		return nil, fmt.Errorf("Invalid base currency %q in %s", rates.Base, path)
+	}
+	for code, rate := range rates.Rates {
+		if !isCurrency(code) || !(rate > 0) || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("Invalid rate %s: %v in %s", code, rate, path)
+		}
+	}
+	return &rates, nil
+}
+
+func (f *ratesFile) rate(code string) (float64, bool) {
+	if code == f.Base {
+		return 1, true
+	}
+	rate, ok := f.Rates[code]
+	return rate, ok
+}
+
+// isCurrency reports whether code looks like an ISO 4217 code: three
+// upper case letters.
+func isCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// currencyRates holds the rates of the rates file, reloaded when the file
+// is modified. When a reload fails, the previous rates are kept, until they
+// are older than maxAge when it is not zero.
+type currencyRates struct {
+	path   string
+	maxAge time.Duration
+
+	mu      sync.Mutex
+	rates   *ratesFile
+	modTime time.Time
+	checked time.Time
+}
+
+func newCurrencyRates(path string, maxAge time.Duration) *currencyRates {
+	r := &currencyRates{path: path, maxAge: maxAge}
+	if path != "" {
+		r.reload()
+	}
+	return r
+}
+
+// reload loads the rates file, with r.mu held or before r is shared.
+func (r *currencyRates) reload() {
+	r.checked = time.Now()
+	info, err := os.Stat(r.path)
+	if err != nil {
+		slog.Error("cannot load currency rates", "error", err)
+		return
+	}
+	r.modTime = info.ModTime()
+
+	rates, err := loadRates(r.path)
+	if err != nil {
+		slog.Error("cannot load currency rates", "error", err)
+		return
+	}
+	r.rates = rates
+	slog.Info("currency rates loaded", "path", r.path, "date", rates.Date, "currencies", len(rates.Rates)+1)
+}
+
+// current returns the rates, after reloading the file if it was modified
+// since the last check, or nil when no rates were loaded.
+func (r *currencyRates) current() *ratesFile {
+	if r == nil || r.path == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checked) >= ratesCheckInterval {
+		r.checked = time.Now()
+		if info, err := os.Stat(r.path); err != nil {
+			slog.Error("cannot check currency rates", "error", err)
+		} else if !info.ModTime().Equal(r.modTime) {
+			r.reload()
+		}
+	}
+	return r.rates
+}
+
+func (r *currencyRates) convert(value float64, from string, to string) (*calculatorpb.ConvertResponse, error) {
+	rates := r.current()
+	if rates == nil {
+		return nil, grpcerr.FailedPrecondition("CONFIG", "currency rates", "No rates file loaded, see CURRENCY_RATES")
+	}
+	if r.maxAge > 0 && time.Since(rates.date) > r.maxAge {
+		return nil, grpcerr.FailedPrecondition("STALE", "currency rates", fmt.Sprintf("The rates of %s are older than %v, see CURRENCY_RATES_MAX_AGE", rates.Date, r.maxAge))
+	}
+
+	fromRate, ok := rates.rate(from)
+	if !ok {
+		return nil, grpcerr.InvalidArgument("from", fmt.Sprintf("Unknown currency %q", from))
+	}
+	toRate, ok := rates.rate(to)
+	if !ok {
+		return nil, grpcerr.InvalidArgument("to", fmt.Sprintf("Unknown currency %q", to))
+	}
+
+	result := value / fromRate * toRate
+	if math.IsInf(result, 0) {
+		return nil, grpcerr.OutOfRange("value", "The result overflows a double")
+	}
+
+	return &calculatorpb.ConvertResponse{
+		Value:     roundConversion(result),
+		Dimension: "currency",
+		RatesDate: rates.Date,
+	}, nil
+}
@@ -0,0 +1,182 @@
+package calculatorserver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeRates writes the rates file at path, modified at modTime.
+func writeRates(t *testing.T, path string, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConvertCurrencies(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	old := time.Now().AddDate(0, 0, -10).Format("2006-01-02")
+
+	tests := []struct {
+		name   string
+		rates  string
+		maxAge time.Duration
+		// noFile does not create the rates file
+		noFile   bool
+		from, to string
+		want     float64
+		wantCode codes.Code
+		// wantType is the type of the PreconditionFailure violation
+		wantType string
+	}{
+		{
+			name:  "from the base",
+			rates: `{"base": "EUR", "date": "` + today + `", "rates": {"USD": 1.1, "GBP": 0.85}}`,
+			from:  "EUR", to: "USD", want: 110,
+		},
+		{
+			name:  "between two rates",
+			rates: `{"base": "EUR", "date": "` + today + `", "rates": {"USD": 1.25, "GBP": 0.5}}`,
+			from:  "USD", to: "GBP", want: 40,
+		},
+		{
+			name:  "unknown currency",
+			rates: `{"base": "EUR", "date": "` + today + `", "rates": {"USD": 1.1}}`,
+			from:  "USD", to: "JPY",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "no rates file",
+			from: "EUR", to: "USD",
+			wantCode: codes.FailedPrecondition,
+			wantType: "CONFIG",
+		},
+		{
+			name:   "missing rates file",
+			noFile: true,
+			from:   "EUR", to: "USD",
+			wantCode: codes.FailedPrecondition,
+			wantType: "CONFIG",
+		},
+		{
+			name:  "invalid rates file",
+			rates: `{"base": "EUR", "date": "` + today + `", "rates": {"USD": -1}}`,
+			from:  "EUR", to: "USD",
+			wantCode: codes.FailedPrecondition,
+			wantType: "CONFIG",
+		},
+		{
+			name:   "stale rates",
+			rates:  `{"base": "EUR", "date": "` + old + `", "rates": {"USD": 1.1}}`,
+			maxAge: 72 * time.Hour,
+			from:   "EUR", to: "USD",
+			wantCode: codes.FailedPrecondition,
+			wantType: "STALE",
+		},
+		{
+			name:   "rates within the maximum age",
+			rates:  `{"base": "EUR", "date": "` + old + `", "rates": {"USD": 1.1}}`,
+			maxAge: 30 * 24 * time.Hour,
+			from:   "EUR", to: "USD", want: 110,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			if tt.rates != "" || tt.noFile {
+				path = filepath.Join(t.TempDir(), "rates.json")
+			}
+			if tt.rates != "" {
+				writeRates(t, path, tt.rates, time.Now())
+			}
+			s := &Server{rates: newCurrencyRates(path, tt.maxAge)}
+
+			res, err := s.convert(&calculatorpb.ConvertRequest{Value: 100, From: tt.from, To: tt.to})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if tt.wantType != "" {
+				failure, ok := grpcerr.PreconditionFailure(err)
+				if !ok || len(failure.GetViolations()) != 1 || failure.GetViolations()[0].GetType() != tt.wantType {
+					t.Errorf("PreconditionFailure = %v, want the type %s", failure, tt.wantType)
+				}
+			}
+			if err == nil && (res.GetValue() != tt.want || res.GetDimension() != "currency") {
+				t.Errorf("convert() = %v %s, want %v currency", res.GetValue(), res.GetDimension(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrencyRatesReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeRates(t, path, `{"base": "EUR", "date": "2026-10-19", "rates": {"USD": 1.1}}`, modTime)
+	r := newCurrencyRates(path, 0)
+
+	tests := []struct {
+		name    string
+		content string
+		modTime time.Time
+		// check forces the check of the file, done every
+		// ratesCheckInterval
+		check    bool
+		wantRate float64
+		wantDate string
+	}{
+		{name: "loaded", wantRate: 1.1, wantDate: "2026-10-19"},
+		{
+			name:    "modified, not checked yet",
+			content: `{"base": "EUR", "date": "2026-10-20", "rates": {"USD": 1.2}}`, modTime: modTime.Add(time.Minute),
+			wantRate: 1.1, wantDate: "2026-10-19",
+		},
+		{name: "modified", check: true, wantRate: 1.2, wantDate: "2026-10-20"},
+		{
+			name:    "same modification time",
+			content: `{"base": "EUR", "date": "2026-10-21", "rates": {"USD": 1.3}}`, modTime: modTime.Add(time.Minute), check: true,
+			wantRate: 1.2, wantDate: "2026-10-20",
+		},
+		{
+			name:    "invalid, previous rates kept",
+			content: `{"base": "EUR"`, modTime: modTime.Add(2 * time.Minute), check: true,
+			wantRate: 1.2, wantDate: "2026-10-20",
+		},
+		{
+			name:    "fixed",
+			content: `{"base": "EUR", "date": "2026-10-22", "rates": {"USD": 1.4}}`, modTime: modTime.Add(3 * time.Minute), check: true,
+			wantRate: 1.4, wantDate: "2026-10-22",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.content != "" {
+				writeRates(t, path, tt.content, tt.modTime)
+			}
+			if tt.check {
+				r.mu.Lock()
+				r.checked = time.Time{}
+				r.mu.Unlock()
+			}
+
+			res, err := r.convert(1, "EUR", "USD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.GetValue() != tt.wantRate || res.GetRatesDate() != tt.wantDate {
+				t.Errorf("rate = %v of %s, want %v of %s", res.GetValue(), res.GetRatesDate(), tt.wantRate, tt.wantDate)
+			}
+		})
+	}
+}
@@ -0,0 +1,448 @@
+package calculatorserver
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+// maxExpressionDepth bounds the nesting of the expressions.
+const maxExpressionDepth = 100
+
+// constants can be used in the expressions, unless a variable has the same
+// name.
+var constants = map[string]float64{
+	"pi": math.Pi,
+	"e":  math.E,
+}
+
+// function is a function of the expressions, with minArgs to maxArgs
+// arguments (no limit when maxArgs is negative).
+type function struct {
+	minArgs int
+	maxArgs int
+	call    func(args []float64) float64
+	// domain reports whether the arguments are valid, when not all of them
+	// are
+	domain func(args []float64) bool
+}
+
+var functions = map[string]function{
+	"sin":  {minArgs: 1, maxArgs: 1, call: func(a []float64) float64 { return math.Sin(a[0]) }},
+	"cos":  {minArgs: 1, maxArgs: 1, call: func(a []float64) float64 { return math.Cos(a[0]) }},
+	"log":  {minArgs: 1, maxArgs: 1, call: func(a []float64) float64 { return math.Log(a[0]) }, domain: func(a []float64) bool { return a[0] > 0 }},
+	"sqrt": {minArgs: 1, maxArgs: 1, call: func(a []float64) float64 { return math.Sqrt(a[0]) }, domain: func(a []float64) bool { return a[0] >= 0 }},
+	"abs":  {minArgs: 1, maxArgs: 1, call: func(a []float64) float64 { return math.Abs(a[0]) }},
+	"min":  {minArgs: 1, maxArgs: -1, call: fold(math.Min)},
+	"max":  {minArgs: 1, maxArgs: -1, call: fold(math.Max)},
+}
+
+func fold(f func(float64, float64) float64) func([]float64) float64 {
+	return func(args []float64) float64 {
+		v := args[0]
+		for _, arg := range args[1:] {
+			v = f(v, arg)
+		}
+		return v
+	}
+}
+
+// expressionError is an error in the expression at pos, the position of a
+// character starting from 1.
+type expressionError struct {
+	pos int
+	msg string
+}
+
+func (e *expressionError) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.pos, e.msg)
+}
+
+// status converts e to an InvalidArgument error, with the position in the
+// metadata of the ErrorInfo detail.
+func (e *expressionError) status() error {
+	description := e.Error()
+	return grpcerr.New(codes.InvalidArgument, grpcerr.ReasonInvalidArgument, "Invalid expression: "+description,
+		map[string]string{"field": "expression", "position": strconv.Itoa(e.pos)},
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       "expression",
+			Description: description,
+		}}},
+	)
+}
+
+// evaluate parses and evaluates expression with the values of variables.
+func evaluate(expression string, variables map[string]float64) (float64, error) {
+	p := &parser{src: []rune(expression)}
+	node, err := p.parse()
+	if err != nil {
+		return 0, err.status()
+	}
+
+	v, err := node.eval(variables)
+	if err != nil {
+		return 0, err.status()
+	}
+	// NaN comes from infinite intermediate results, e.g. 10^400 - 10^400
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, grpcerr.OutOfRange("expression", "The result is too large")
+	}
+	return v, nil
+}
+
+// node is a node of the syntax tree of an expression.
+type node interface {
+	eval(variables map[string]float64) (float64, *expressionError)
+}
+
+type numberNode struct {
+	value float64
+}
+
+type identNode struct {
+	pos  int
+	name string
+}
+
+type unaryNode struct {
+	op      rune
+	operand node
+}
+
+type binaryNode struct {
+	pos         int
+	op          rune
+	left, right node
+}
+
+type callNode struct {
+	pos  int
+	name string
+	args []node
+}
+
+func (n *numberNode) eval(map[string]float64) (float64, *expressionError) {
+	return n.value, nil
+}
+
+func (n *identNode) eval(variables map[string]float64) (float64, *expressionError) {
+	if v, ok := variables[n.name]; ok {
+		return v, nil
+	}
+	if v, ok := constants[n.name]; ok {
+		return v, nil
+	}
+	return 0, &expressionError{pos: n.pos, msg: fmt.Sprintf("unknown variable %q", n.name)}
+}
+
+func (n *unaryNode) eval(variables map[string]float64) (float64, *expressionError) {
+	v, err := n.operand.eval(variables)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == '-' {
+		return -v, nil
+	}
+	return v, nil
+}
+
+func (n *binaryNode) eval(variables map[string]float64) (float64, *expressionError) {
+	left, err := n.left.eval(variables)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(variables)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	case '/':
+		if right == 0 {
+			return 0, &expressionError{pos: n.pos, msg: "division by zero"}
+		}
+		return left / right, nil
+	case '%':
+		if right == 0 {
+			return 0, &expressionError{pos: n.pos, msg: "division by zero"}
+		}
+		return math.Mod(left, right), nil
+	default: // '^'
+		v := math.Pow(left, right)
+		if math.IsNaN(v) {
+			return 0, &expressionError{pos: n.pos, msg: "negative number to a fractional power"}
+		}
+		if math.IsInf(v, 0) && left == 0 {
+			return 0, &expressionError{pos: n.pos, msg: "division by zero"}
+		}
+		return v, nil
+	}
+}
+
+func (n *callNode) eval(variables map[string]float64) (float64, *expressionError) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(variables)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+
+	f := functions[n.name]
+	if f.domain != nil && !f.domain(args) {
+		return 0, &expressionError{pos: n.pos, msg: fmt.Sprintf("argument out of the domain of %s", n.name)}
+	}
+	return f.call(args), nil
+}
+
+// parser is a recursive descent parser of the grammar:
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/" | "%") unary }
+//	unary   = ("+" | "-") unary | power
+//	power   = primary [ "^" unary ]
+//	primary = number | ident | ident "(" expr { "," expr } ")" | "(" expr ")"
+//
+// so that ^ is right associative and binds tighter than the unary minus:
+// -2^2 is -4.
+type parser struct {
+	src   []rune
+	pos   int
+	depth int
+}
+
+func (p *parser) parse() (node, *expressionError) {
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	return n, nil
+}
+
+func (p *parser) expr() (node, *expressionError) {
+	if p.depth++; p.depth > maxExpressionDepth {
+		return nil, p.errorf("expression nested too deeply")
+	}
+	defer func() { p.depth-- }()
+
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, pos, ok := p.operator("+-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: pos, op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) term() (node, *expressionError) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, pos, ok := p.operator("*/%")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: pos, op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, *expressionError) {
+	if op, _, ok := p.operator("+-"); ok {
+		if p.depth++; p.depth > maxExpressionDepth {
+			return nil, p.errorf("expression nested too deeply")
+		}
+		defer func() { p.depth-- }()
+
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.power()
+}
+
+func (p *parser) power() (node, *expressionError) {
+	base, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	op, pos, ok := p.operator("^")
+	if !ok {
+		return base, nil
+	}
+
+	if p.depth++; p.depth > maxExpressionDepth {
+		return nil, p.errorf("expression nested too deeply")
+	}
+	defer func() { p.depth-- }()
+
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &binaryNode{pos: pos, op: op, left: base, right: exponent}, nil
+}
+
+func (p *parser) primary() (node, *expressionError) {
+	p.skipSpaces()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		n, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case unicode.IsDigit(c) || c == '.':
+		return p.number()
+	case unicode.IsLetter(c) || c == '_':
+		return p.identOrCall()
+	}
+	return nil, p.errorf("unexpected %q", c)
+}
+
+func (p *parser) number() (node, *expressionError) {
+	start := p.pos
+	for p.pos < len(p.src) && (unicode.IsDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+		p.pos++
+	}
+	// exponent, e.g. 1.5e-3
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		end := p.pos + 1
+		if end < len(p.src) && (p.src[end] == '+' || p.src[end] == '-') {
+			end++
+		}
+		if end < len(p.src) && unicode.IsDigit(p.src[end]) {
+			for end < len(p.src) && unicode.IsDigit(p.src[end]) {
+				end++
+			}
+			p.pos = end
+		}
+	}
+
+	text := string(p.src[start:p.pos])
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, &expressionError{pos: start + 1, msg: fmt.Sprintf("invalid number %q", text)}
+	}
+	return &numberNode{value: v}, nil
+}
+
+func (p *parser) identOrCall() (node, *expressionError) {
+	start := p.pos
+	for p.pos < len(p.src) && (unicode.IsLetter(p.src[p.pos]) || unicode.IsDigit(p.src[p.pos]) || p.src[p.pos] == '_') {
+		p.pos++
+	}
+	name := string(p.src[start:p.pos])
+
+	p.skipSpaces()
+	if p.pos >= len(p.src) || p.src[p.pos] != '(' {
+		return &identNode{pos: start + 1, name: name}, nil
+	}
+
+	f, ok := functions[name]
+	if !ok {
+		return nil, &expressionError{pos: start + 1, msg: fmt.Sprintf("unknown function %q", name)}
+	}
+	p.pos++
+
+	call := &callNode{pos: start + 1, name: name}
+	for {
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+
+		p.skipSpaces()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	if len(call.args) < f.minArgs || f.maxArgs >= 0 && len(call.args) > f.maxArgs {
+		return nil, &expressionError{pos: call.pos, msg: fmt.Sprintf("wrong number of arguments for %s: %d", name, len(call.args))}
+	}
+	return call, nil
+}
+
+// operator consumes the next character if it is one of ops.
+func (p *parser) operator(ops string) (rune, int, bool) {
+	p.skipSpaces()
+	if p.pos >= len(p.src) {
+		return 0, 0, false
+	}
+	for _, op := range ops {
+		if p.src[p.pos] == op {
+			p.pos++
+			return op, p.pos, true
+		}
+	}
+	return 0, 0, false
+}
+
+func (p *parser) expect(c rune) *expressionError {
+	p.skipSpaces()
+	if p.pos >= len(p.src) {
+		return p.errorf("expected %q, got end of expression", c)
+	}
+	if p.src[p.pos] != c {
+		return p.errorf("expected %q, got %q", c, p.src[p.pos])
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// errorf returns an error at the current position.
+func (p *parser) errorf(format string, args ...interface{}) *expressionError {
+	return &expressionError{pos: p.pos + 1, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,91 @@
+package calculatorserver
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEvaluate(t *testing.T) {
+	variables := map[string]float64{"x": 3, "pi": 3}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       float64
+		wantCode   codes.Code
+		// wantPos is the position of the error of an invalid expression
+		wantPos int
+	}{
+		{name: "unary minus before power", expression: "-2^2", want: -4},
+		{name: "power is right associative", expression: "2^3^2", want: 512},
+		{name: "minus is left associative", expression: "1-2-3", want: -4},
+		{name: "division is left associative", expression: "8/2/2", want: 2},
+		{name: "precedence", expression: "2+3*4", want: 14},
+		{name: "parentheses", expression: "(2+3)*4", want: 20},
+		{name: "negative exponent", expression: "2^-1", want: 0.5},
+		{name: "double minus", expression: "- -1", want: 1},
+		{name: "modulo", expression: "7 % 4", want: 3},
+		{name: "exponent notation", expression: "1.5e-3 * 2", want: 0.003},
+		{name: "variable", expression: "x * 2", want: 6},
+		{name: "variable before constant", expression: "pi", want: 3},
+		{name: "constant", expression: "e", want: math.E},
+		{name: "function", expression: "sqrt(16) + abs(-1)", want: 5},
+		{name: "variadic function", expression: "min(3, 1, 2) + max(4)", want: 5},
+
+		{name: "division by zero", expression: "1/0", wantCode: codes.InvalidArgument, wantPos: 2},
+		{name: "modulo by zero", expression: "1 % (x-3)", wantCode: codes.InvalidArgument, wantPos: 3},
+		{name: "zero to a negative power", expression: "0^-1", wantCode: codes.InvalidArgument, wantPos: 2},
+		{name: "fractional power of a negative number", expression: "(-8)^0.5", wantCode: codes.InvalidArgument, wantPos: 5},
+		{name: "too many arguments", expression: "1 + sin(1, 2)", wantCode: codes.InvalidArgument, wantPos: 5},
+		{name: "no argument", expression: "min()", wantCode: codes.InvalidArgument, wantPos: 5},
+		{name: "unknown function", expression: "2 * foo(1)", wantCode: codes.InvalidArgument, wantPos: 5},
+		{name: "unknown variable", expression: "x + y", wantCode: codes.InvalidArgument, wantPos: 5},
+		{name: "out of domain", expression: "log(0)", wantCode: codes.InvalidArgument, wantPos: 1},
+		{name: "unexpected end", expression: "1 +", wantCode: codes.InvalidArgument, wantPos: 4},
+		{name: "missing parenthesis", expression: "(1", wantCode: codes.InvalidArgument, wantPos: 3},
+		{name: "unexpected character", expression: "2 3", wantCode: codes.InvalidArgument, wantPos: 3},
+		{name: "empty", expression: "", wantCode: codes.InvalidArgument, wantPos: 1},
+		{name: "overflow", expression: "10^400", wantCode: codes.OutOfRange},
+
+		{name: "maximum depth", expression: strings.Repeat("(", maxExpressionDepth-1) + "1" + strings.Repeat(")", maxExpressionDepth-1), want: 1},
+		{name: "nested too deeply", expression: strings.Repeat("(", maxExpressionDepth) + "1" + strings.Repeat(")", maxExpressionDepth), wantCode: codes.InvalidArgument, wantPos: maxExpressionDepth + 1},
+		{name: "too many unary minus", expression: strings.Repeat("-", 2*maxExpressionDepth) + "1", wantCode: codes.InvalidArgument, wantPos: maxExpressionDepth + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := evaluate(tt.expression, variables)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("evaluate(%q) = %v, %v, want code %v", tt.expression, v, err, tt.wantCode)
+			}
+			if err == nil {
+				if math.Abs(v-tt.want) > 1e-12 {
+					t.Errorf("evaluate(%q) = %v, want %v", tt.expression, v, tt.want)
+				}
+				return
+			}
+			if tt.wantPos == 0 {
+				return
+			}
+
+			pos := strconv.Itoa(tt.wantPos)
+			info, ok := grpcerr.ErrorInfo(err)
+			if !ok || info.GetMetadata()["field"] != "expression" || info.GetMetadata()["position"] != pos {
+				t.Errorf("ErrorInfo = %v, want the position %s of %v", info, pos, err)
+			}
+			req, ok := grpcerr.BadRequest(err)
+			if !ok || len(req.GetFieldViolations()) != 1 {
+				t.Fatalf("BadRequest = %v", req)
+			}
+			if v := req.GetFieldViolations()[0]; v.GetField() != "expression" || !strings.HasPrefix(v.GetDescription(), "at position "+pos+":") {
+				t.Errorf("violation = %v, want the position %s", v, pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package calculatorserver
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"math/bits"
+	"sort"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+)
+
+const (
+	// smallPrimesLimit bounds the primes found by trial division before
+	// using Pollard's rho.
+	smallPrimesLimit = 1000
+	// millerRabinRounds is the number of Miller-Rabin rounds of the
+	// primality test, on top of the Baillie-PSW test of ProbablyPrime.
+	millerRabinRounds = 20
+	// rhoBatch is the number of iterations of Pollard's rho between two
+	// gcd, and two checks of the cancellation.
+	rhoBatch = 128
+)
+
+var smallPrimes = sieve(smallPrimesLimit)
+
+var bigOne = big.NewInt(1)
+
+// primePower is a prime factor and its multiplicity.
+type primePower struct {
+	prime        *big.Int
+	multiplicity int
+}
+
+func (f primePower) response() *calculatorpb.PrimeNumberDecompositionResponse {
+	res := &calculatorpb.PrimeNumberDecompositionResponse{
+		BigPrimeFactor: f.prime.String(),
+		Multiplicity:   int32(f.multiplicity),
+	}
+	if f.prime.IsInt64() {
+		res.PrimeFactor = f.prime.Int64()
+	}
+	return res
+}
+
+// sieve returns the primes lower than n.
+func sieve(n int) []*big.Int {
+	composite := make([]bool, n)
+	var primes []*big.Int
+	for i := 2; i < n; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, big.NewInt(int64(i)))
+		for j := i * i; j < n; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// factorize returns the prime factors of n >= 1 in increasing order, or
+// the error of ctx when it is done first. The progress is the number of
+// bits of the factors found over the number of bits of n.
+func factorize(ctx context.Context, n *big.Int, progress progressFunc) ([]primePower, error) {
+	counts := map[string]*primePower{}
+	total, found := n.BitLen(), 0
+	add := func(p *big.Int) {
+		found += p.BitLen()
+		progress.report(math.Min(float64(found)/float64(total), 1))
+
+		key := p.String()
+		if pp, ok := counts[key]; ok {
+			pp.multiplicity++
+			return
+		}
+		counts[key] = &primePower{prime: new(big.Int).Set(p), multiplicity: 1}
+	}
+
+	n = new(big.Int).Set(n)
+	q, r := new(big.Int), new(big.Int)
+	for _, p := range smallPrimes {
+		if new(big.Int).Mul(p, p).Cmp(n) > 0 {
+			break
+		}
+		for {
+			q.QuoRem(n, p, r)
+			if r.Sign() != 0 {
+				break
+			}
+			add(p)
+			n.Set(q)
+		}
+	}
+
+	// the remaining composite numbers are split by Pollard's rho
+	pending := []*big.Int{n}
+	for len(pending) > 0 {
+		m := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if m.Cmp(bigOne) == 0 {
+			continue
+		}
+		if m.ProbablyPrime(millerRabinRounds) {
+			add(m)
+			continue
+		}
+		// the cycle of Pollard's rho is not always found for the powers of
+		// a prime, nor in time for those of a large one
+		if root, k := perfectPower(m); root != nil {
+			for i := 0; i < k; i++ {
+				pending = append(pending, root)
+			}
+			continue
+		}
+
+		if m.IsUint64() {
+			d, err := pollardRho64(ctx, m.Uint64())
+			if err != nil {
+				return nil, err
+			}
+			pending = append(pending, new(big.Int).SetUint64(d), new(big.Int).SetUint64(m.Uint64()/d))
+			continue
+		}
+		d, err := pollardRho(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, d, new(big.Int).Quo(m, d))
+	}
+
+	factors := make([]primePower, 0, len(counts))
+	for _, pp := range counts {
+		factors = append(factors, *pp)
+	}
+	sort.Slice(factors, func(i, j int) bool {
+		return factors[i].prime.Cmp(factors[j].prime) < 0
+	})
+	return factors, nil
+}
+
+// perfectPower returns the root r and the prime k of m = r^k, the
+// smallest k, or nil when m is not a perfect power. m has no factor in
+// smallPrimes, so that r > 2^9 and k < BitLen/9.
+func perfectPower(m *big.Int) (*big.Int, int) {
+	for _, p := range smallPrimes {
+		k := p.Int64()
+		if 9*k >= int64(m.BitLen()) {
+			break
+		}
+		r := root(m, k)
+		if new(big.Int).Exp(r, p, nil).Cmp(m) == 0 {
+			return r, int(k)
+		}
+	}
+	return nil, 0
+}
+
+// root returns the integer k-th root of n > 0, by Newton's method from a
+// value greater than the root.
+func root(n *big.Int, k int64) *big.Int {
+	bigK, bigK1 := big.NewInt(k), big.NewInt(k-1)
+	x := new(big.Int).Lsh(bigOne, uint((int64(n.BitLen())+k-1)/k))
+	y, t := new(big.Int), new(big.Int)
+	for {
+		// y = ((k-1) x + n / x^(k-1)) / k
+		t.Exp(x, bigK1, nil)
+		t.Quo(n, t)
+		y.Mul(x, bigK1)
+		y.Add(y, t)
+		y.Quo(y, bigK)
+		if y.Cmp(x) >= 0 {
+			return x
+		}
+		x.Set(y)
+	}
+}
+
+// pollardRho returns a non trivial divisor of n, an odd composite number,
+// with Brent's variant of Pollard's rho algorithm.
+func pollardRho(ctx context.Context, n *big.Int) (*big.Int, error) {
+	x, y, ys := new(big.Int), new(big.Int), new(big.Int)
+	q, g, diff := new(big.Int), new(big.Int), new(big.Int)
+
+	// f(v) = v^2 + c mod n, with another c when the cycle is n itself
+	for c := int64(1); ; c++ {
+		bigC := big.NewInt(c)
+		f := func(v *big.Int) {
+			v.Mul(v, v)
+			v.Add(v, bigC)
+			v.Mod(v, n)
+		}
+
+		y.SetInt64(2)
+		q.SetInt64(1)
+		g.SetInt64(1)
+		for r := 1; g.Cmp(bigOne) == 0; r *= 2 {
+			x.Set(y)
+			for i := 0; i < r; i++ {
+				if i%rhoBatch == 0 && ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				f(y)
+			}
+			for k := 0; k < r && g.Cmp(bigOne) == 0; k += rhoBatch {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+
+				ys.Set(y)
+				for i := 0; i < rhoBatch && i < r-k; i++ {
+					f(y)
+					q.Mul(q, diff.Abs(diff.Sub(x, y)))
+					q.Mod(q, n)
+				}
+				g.GCD(nil, nil, q, n)
+			}
+		}
+
+		// the batch went past the divisor, find it one step at a time
+		if g.Cmp(n) == 0 {
+			for {
+				f(ys)
+				g.GCD(nil, nil, diff.Abs(diff.Sub(x, ys)), n)
+				if g.Cmp(bigOne) != 0 {
+					break
+				}
+			}
+		}
+
+		if g.Cmp(n) != 0 {
+			return new(big.Int).Set(g), nil
+		}
+	}
+}
+
+// pollardRho64 is pollardRho for the numbers fitting in an uint64, about
+// ten times faster.
+func pollardRho64(ctx context.Context, n uint64) (uint64, error) {
+	mulMod := func(a, b uint64) uint64 {
+		hi, lo := bits.Mul64(a, b)
+		_, rem := bits.Div64(hi%n, lo, n)
+		return rem
+	}
+	absDiff := func(a, b uint64) uint64 {
+		if a > b {
+			return a - b
+		}
+		return b - a
+	}
+
+	for c := uint64(1); ; c++ {
+		f := func(v uint64) uint64 {
+			v = mulMod(v, v) + c
+			if v >= n || v < c {
+				v -= n
+			}
+			return v
+		}
+
+		var x, ys uint64
+		y, q, g := uint64(2), uint64(1), uint64(1)
+		for r := 1; g == 1; r *= 2 {
+			x = y
+			for i := 0; i < r; i++ {
+				if i%rhoBatch == 0 && ctx.Err() != nil {
+					return 0, ctx.Err()
+				}
+				y = f(y)
+			}
+			for k := 0; k < r && g == 1; k += rhoBatch {
+				if err := ctx.Err(); err != nil {
+					return 0, err
+				}
+
+				ys = y
+				for i := 0; i < rhoBatch && i < r-k; i++ {
+					y = f(y)
+					q = mulMod(q, absDiff(x, y))
+				}
+				g = gcd64(q, n)
+			}
+		}
+
+		// the batch went past the divisor, find it one step at a time
+		if g == n {
+			for {
+				ys = f(ys)
+				if g = gcd64(absDiff(x, ys), n); g != 1 {
+					break
+				}
+			}
+		}
+
+		if g != n {
+			return g, nil
+		}
+	}
+}
+
+func gcd64(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,82 @@
+package calculatorserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFactorize(t *testing.T) {
+	tests := []struct {
+		name string
+		n    string
+		// want is the prime factors, as p^multiplicity when it is above 1
+		want string
+	}{
+		{name: "one", n: "1", want: ""},
+		{name: "small prime", n: "2", want: "2"},
+		{name: "small factors", n: "120", want: "2^3 3 5"},
+		{name: "prime", n: "1000000007", want: "1000000007"},
+		{name: "Mersenne prime", n: "170141183460469231731687303715884105727", want: "170141183460469231731687303715884105727"},
+		{name: "semiprime", n: "1000000016000000063", want: "1000000007 1000000009"},
+		{name: "square of a prime", n: "4611686014132420609", want: "2147483647^2"},
+		{name: "cube of a 61 bits prime", n: "12259964326927110850916040267783483001021757281745764351", want: "2305843009213693951^3"},
+		{name: "fifth power of a 61 bits prime", n: "65185151242703554619242496846829354909582831807147587369656424969918784727574340904001994751", want: "2305843009213693951^5"},
+		{name: "sixth power of a prime", n: "98079714341385330254404631364738284897724378381211926529", want: "2147483647^6"},
+		{name: "seventh power of a prime", n: "1000021000189000945002835005103005103002187", want: "1000003^7"},
+		{name: "above 64 bits", n: "18446744073709551617", want: "274177 67280421310721"},
+		{name: "large semiprime", n: "4951760154835678088235319297", want: "2147483647 2305843009213693951"},
+		{name: "power of ten", n: "1" + strings.Repeat("0", 100), want: "2^100 5^100"},
+		{name: "several factors", n: "600851475143", want: "71 839 1471 6857"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, ok := new(big.Int).SetString(tt.n, 10)
+			if !ok {
+				t.Fatalf("invalid number %s", tt.n)
+			}
+			var last float64
+			progress := func(done float64) {
+				if done < last {
+					t.Errorf("progress %v after %v", done, last)
+				}
+				last = done
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			factors, err := factorize(ctx, n, progress)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, pp := range factors {
+				if pp.multiplicity == 1 {
+					got = append(got, pp.prime.String())
+				} else {
+					got = append(got, fmt.Sprintf("%v^%d", pp.prime, pp.multiplicity))
+				}
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("factorize(%s) = %s, want %s", tt.n, strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestFactorizeCanceled(t *testing.T) {
+	// the product of two primes of 30 digits, too large for Pollard's rho
+	n, _ := new(big.Int).SetString("189620700613125325959116839007395234454467716598457179234021", 10)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := factorize(ctx, n, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("factorize() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
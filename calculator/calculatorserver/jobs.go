@@ -0,0 +1,586 @@
+package calculatorserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultQueueSize    = 100
+	defaultJobRetention = 24 * time.Hour
+	defaultMaxJobs      = 10000
+	defaultJobsPageSize = 100
+	queueFullRetryDelay = 5 * time.Second
+	canceledJobMessage  = "The job was canceled"
+)
+
+// progressFunc reports the fraction, from 0 to 1, of a computation done.
+// A nil progressFunc ignores it.
+type progressFunc func(done float64)
+
+func (f progressFunc) report(done float64) {
+	if f != nil {
+		f(done)
+	}
+}
+
+// JobOptions configures the jobs.
+type JobOptions struct {
+	// Workers is the number of jobs run at the same time, the number of
+	// CPUs when zero.
+	Workers int
+	// QueueSize is the number of jobs waiting for a worker, 100 when zero.
+	QueueSize int
+	// Dir stores the jobs, one JSON file by job, so that they survive a
+	// restart: the jobs that were not done are run again. The jobs are
+	// only kept in memory when it is empty.
+	Dir string
+	// Retention is how long the jobs are kept once done, 24 hours when
+	// zero.
+	Retention time.Duration
+	// MaxJobs is the number of jobs kept, 10000 when zero: beyond it, the
+	// jobs done first are removed before their retention.
+	MaxJobs int
+}
+
+// jobOptionsFromEnv reads the JobOptions from the JOB_WORKERS,
+// JOB_QUEUE_SIZE, JOB_DIR, JOB_RETENTION and JOB_MAX_JOBS environment
+// variables.
+func jobOptionsFromEnv() (JobOptions, error) {
+	opts := JobOptions{Dir: os.Getenv("JOB_DIR")}
+
+	for key, dst := range map[string]*int{"JOB_WORKERS": &opts.Workers, "JOB_QUEUE_SIZE": &opts.QueueSize, "JOB_MAX_JOBS": &opts.MaxJobs} {
+		if v := os.Getenv(key); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return JobOptions{}, fmt.Errorf("Invalid %s %q", key, v)
+			}
+			*dst = n
+		}
+	}
+	if v := os.Getenv("JOB_RETENTION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return JobOptions{}, fmt.Errorf("Invalid JOB_RETENTION %q: %v", v, err)
+		}
+		opts.Retention = d
+	}
+	return opts, nil
+}
+
+// job is a job of the jobManager. Its fields are guarded by the mutex of
+// the jobManager.
+type job struct {
+	info *calculatorpb.Job
+	// cancel stops the computation of a running job
+	cancel context.CancelFunc
+	// done is closed once the job is done
+	done chan struct{}
+}
+
+// jobExecutor computes a job, and returns a Job whose only field set is
+// the result.
+type jobExecutor func(ctx context.Context, req *calculatorpb.SubmitJobRequest, progress progressFunc) (*calculatorpb.Job, error)
+
+// jobManager runs the jobs with a pool of workers reading a bounded queue.
+type jobManager struct {
+	execute   jobExecutor
+	dir       string
+	retention time.Duration
+	maxJobs   int
+	queue     chan *job
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// newJobManager starts the workers, after loading the jobs of opts.Dir.
+func newJobManager(opts JobOptions, execute jobExecutor) (*jobManager, error) {
+	if opts.Workers == 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.QueueSize == 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.Retention == 0 {
+		opts.Retention = defaultJobRetention
+	}
+	if opts.MaxJobs == 0 {
+		opts.MaxJobs = defaultMaxJobs
+	}
+
+	m := &jobManager{
+		execute:   execute,
+		dir:       opts.Dir,
+		retention: opts.Retention,
+		maxJobs:   opts.MaxJobs,
+		queue:     make(chan *job, opts.QueueSize),
+		jobs:      map[string]*job{},
+	}
+
+	pending, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		go func() {
+			for j := range m.queue {
+				m.run(j)
+			}
+		}()
+	}
+	go func() {
+		for _, j := range pending {
+			m.queue <- j
+		}
+	}()
+	return m, nil
+}
+
+// load reads the jobs stored in m.dir, and returns those to run again.
+func (m *jobManager) load() ([]*job, error) {
+	if m.dir == "" {
+		return nil, nil
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(m.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*job
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		info := &calculatorpb.Job{}
+		if err := protojson.Unmarshal(b, info); err != nil {
+			return nil, fmt.Errorf("Cannot parse job %s: %v", path, err)
+		}
+
+		j := &job{info: info, done: make(chan struct{})}
+		switch info.GetState() {
+		case calculatorpb.JobState_QUEUED, calculatorpb.JobState_RUNNING:
+			info.State = calculatorpb.JobState_QUEUED
+			info.Progress = 0
+			info.StartTime = nil
+			pending = append(pending, j)
+		default:
+			close(j.done)
+		}
+		m.jobs[info.GetId()] = j
+	}
+
+	sort.Slice(pending, func(i, k int) bool {
+		return pending[i].info.GetCreateTime().AsTime().Before(pending[k].info.GetCreateTime().AsTime())
+	})
+	slog.Info("jobs loaded", "dir", m.dir, "jobs", len(m.jobs), "pending", len(pending))
+	return pending, nil
+}
+
+// save stores the job, with m.mu held so that the states are stored in
+// order.
+func (m *jobManager) save(j *job) {
+	if m.dir == "" {
+		return
+	}
+
+	b, err := protojson.Marshal(j.info)
+	if err == nil {
+		path := filepath.Join(m.dir, j.info.GetId()+".json")
+		if err = os.WriteFile(path+".tmp", b, 0o644); err == nil {
+			err = os.Rename(path+".tmp", path)
+		}
+	}
+	if err != nil {
+		slog.Error("cannot store job", "id", j.info.GetId(), "error", err)
+	}
+}
+
+// sweep removes the jobs done for longer than the retention, then the
+// jobs done first while there are more than maxJobs, with m.mu held.
+func (m *jobManager) sweep() {
+	limit := time.Now().Add(-m.retention)
+	var done []*job
+	for id, j := range m.jobs {
+		end := j.info.GetEndTime()
+		switch {
+		case end == nil:
+		case end.AsTime().After(limit):
+			done = append(done, j)
+		default:
+			m.remove(id)
+		}
+	}
+
+	if excess := len(m.jobs) - m.maxJobs; excess > 0 {
+		sort.Slice(done, func(i, k int) bool {
+			return done[i].info.GetEndTime().AsTime().Before(done[k].info.GetEndTime().AsTime())
+		})
+		if excess > len(done) {
+			excess = len(done)
+		}
+		for _, j := range done[:excess] {
+			m.remove(j.info.GetId())
+		}
+	}
+}
+
+// remove removes the job of id, with m.mu held.
+func (m *jobManager) remove(id string) {
+	delete(m.jobs, id)
+	if m.dir != "" {
+		if err := os.Remove(filepath.Join(m.dir, id+".json")); err != nil && !os.IsNotExist(err) {
+			slog.Error("cannot remove job", "id", id, "error", err)
+		}
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (m *jobManager) submit(req *calculatorpb.SubmitJobRequest) (*calculatorpb.Job, error) {
+	j := &job{
+		info: &calculatorpb.Job{
+			Id:         newJobID(),
+			State:      calculatorpb.JobState_QUEUED,
+			CreateTime: timestamppb.Now(),
+			Request:    req,
+		},
+		done: make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	select {
+	case m.queue <- j:
+	default:
+		return nil, grpcerr.ResourceExhausted(grpcerr.ReasonQueueFull, "The queue of the jobs is full", queueFullRetryDelay)
+	}
+	m.jobs[j.info.GetId()] = j
+	m.save(j)
+	// the new job is not done, so it is kept
+	m.sweep()
+	return proto.Clone(j.info).(*calculatorpb.Job), nil
+}
+
+// run computes the job, unless it was canceled while queued.
+func (m *jobManager) run(j *job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m.mu.Lock()
+	if j.info.GetState() != calculatorpb.JobState_QUEUED {
+		m.mu.Unlock()
+		return
+	}
+	j.info.State = calculatorpb.JobState_RUNNING
+	j.info.StartTime = timestamppb.Now()
+	j.cancel = cancel
+	m.save(j)
+	req := j.info.GetRequest()
+	m.mu.Unlock()
+
+	res, err := m.executeSafely(ctx, req, func(done float64) {
+		m.mu.Lock()
+		j.info.Progress = done
+		m.mu.Unlock()
+	})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j.cancel = nil
+	j.info.EndTime = timestamppb.Now()
+	switch {
+	case ctx.Err() != nil:
+		j.info.State = calculatorpb.JobState_CANCELED
+		j.info.Result = &calculatorpb.Job_Error{Error: status.New(codes.Canceled, canceledJobMessage).Proto()}
+	case err != nil:
+		j.info.State = calculatorpb.JobState_FAILED
+		j.info.Result = &calculatorpb.Job_Error{Error: status.Convert(err).Proto()}
+	default:
+		j.info.State = calculatorpb.JobState_SUCCEEDED
+		j.info.Progress = 1
+		j.info.Result = res.Result
+	}
+	m.save(j)
+	close(j.done)
+}
+
+// executeSafely executes the job, turning a panic into an Internal error.
+func (m *jobManager) executeSafely(ctx context.Context, req *calculatorpb.SubmitJobRequest, progress progressFunc) (res *calculatorpb.Job, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.LogAttrs(ctx, slog.LevelError, "recovered from panic",
+				slog.String("method", "job"),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())),
+			)
+			err = grpcerr.Internal("Internal error")
+		}
+	}()
+	return m.execute(ctx, req, progress)
+}
+
+func (m *jobManager) get(id string) (*job, *calculatorpb.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, nil, grpcerr.NotFound("job", id)
+	}
+	return j, proto.Clone(j.info).(*calculatorpb.Job), nil
+}
+
+// cancel cancels a queued job, and stops the computation of a running one:
+// it is CANCELED once the computation returns.
+func (m *jobManager) cancel(id string) (*calculatorpb.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, grpcerr.NotFound("job", id)
+	}
+
+	switch j.info.GetState() {
+	case calculatorpb.JobState_QUEUED:
+		j.info.State = calculatorpb.JobState_CANCELED
+		j.info.EndTime = timestamppb.Now()
+		j.info.Result = &calculatorpb.Job_Error{Error: status.New(codes.Canceled, canceledJobMessage).Proto()}
+		m.save(j)
+		close(j.done)
+	case calculatorpb.JobState_RUNNING:
+		j.cancel()
+	default:
+		return nil, grpcerr.FailedPrecondition("STATE", "job "+id, fmt.Sprintf("The job is already %v", j.info.GetState()))
+	}
+	return proto.Clone(j.info).(*calculatorpb.Job), nil
+}
+
+// wait returns the job once it is done, or after timeout when it is not
+// zero.
+func (m *jobManager) wait(ctx context.Context, id string, timeout time.Duration) (*calculatorpb.Job, error) {
+	j, _, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-j.done:
+	case <-expired:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	_, info, err := m.get(id)
+	return info, err
+}
+
+// pageKey orders the jobs, the most recent first.
+func pageKey(info *calculatorpb.Job) string {
+	// zero padded, so that the keys sort as the times
+	return fmt.Sprintf("%020d/%s", info.GetCreateTime().AsTime().UnixNano(), info.GetId())
+}
+
+func (m *jobManager) list(req *calculatorpb.ListJobsRequest) (*calculatorpb.ListJobsResponse, error) {
+	size := int(req.GetPageSize())
+	if size == 0 {
+		size = defaultJobsPageSize
+	}
+
+	var after string
+	if token := req.GetPageToken(); token != "" {
+		b, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || !strings.Contains(string(b), "/") {
+			return nil, grpcerr.InvalidArgument("page_token", "Cannot parse the page token")
+		}
+		after = string(b)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep()
+
+	var jobs []*job
+	for _, j := range m.jobs {
+		if req.GetState() != calculatorpb.JobState_JOB_STATE_UNSPECIFIED && j.info.GetState() != req.GetState() {
+			continue
+		}
+		if after != "" && pageKey(j.info) >= after {
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return pageKey(jobs[i].info) > pageKey(jobs[k].info)
+	})
+
+	res := &calculatorpb.ListJobsResponse{}
+	if len(jobs) > size {
+		jobs = jobs[:size]
+		res.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(pageKey(jobs[size-1].info)))
+	}
+	for _, j := range jobs {
+		res.Jobs = append(res.Jobs, listedJob(j.info))
+	}
+	return res, nil
+}
+
+// listedJob returns a copy of info without its request, which can be as
+// large as a matrix, with the mutex of the jobManager held.
+func listedJob(info *calculatorpb.Job) *calculatorpb.Job {
+	req := info.Request
+	info.Request = nil
+	listed := proto.Clone(info).(*calculatorpb.Job)
+	info.Request = req
+	return listed
+}
+
+// checkJob checks the request of a job before it is queued.
+func checkJob(req *calculatorpb.SubmitJobRequest) error {
+	switch job := req.GetJob().(type) {
+	case *calculatorpb.SubmitJobRequest_PrimeNumberDecomposition:
+		_, err := decompositionNumber(job.PrimeNumberDecomposition)
+		return err
+	case *calculatorpb.SubmitJobRequest_Matrix:
+		_, _, err := jobMatrices(job.Matrix)
+		return err
+	default:
+		return grpcerr.InvalidArgument("job", "Must be set")
+	}
+}
+
+// jobMatrices returns the operands of a MATRIX job.
+func jobMatrices(job *calculatorpb.MatrixJob) (matrix, matrix, error) {
+	var a, b matrix
+	var err error
+	for _, row := range job.GetA() {
+		if a, err = appendRow(a, "matrix.a", row.GetValues()); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, row := range job.GetB() {
+		if b, err = appendRow(b, "matrix.b", row.GetValues()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := checkOperands(job.GetOperation(), a, b); err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+// executeJob is the jobExecutor of the Server.
+func (s *Server) executeJob(ctx context.Context, req *calculatorpb.SubmitJobRequest, progress progressFunc) (*calculatorpb.Job, error) {
+	switch job := req.GetJob().(type) {
+	case *calculatorpb.SubmitJobRequest_PrimeNumberDecomposition:
+		n, err := decompositionNumber(job.PrimeNumberDecomposition)
+		if err != nil {
+			return nil, err
+		}
+		factors, err := s.factorizations.get(ctx, n.String(), func(ctx context.Context) ([]primePower, error) {
+			return factorize(ctx, n, progress)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		res := &calculatorpb.PrimeFactors{}
+		for _, f := range factors {
+			res.Factors = append(res.Factors, f.response())
+		}
+		return &calculatorpb.Job{Result: &calculatorpb.Job_PrimeFactors{PrimeFactors: res}}, nil
+
+	case *calculatorpb.SubmitJobRequest_Matrix:
+		a, b, err := jobMatrices(job.Matrix)
+		if err != nil {
+			return nil, err
+		}
+		x, det, err := computeMatrix(ctx, job.Matrix.GetOperation(), a, b, progress)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &calculatorpb.MatrixResult{Determinant: det}
+		for _, row := range x {
+			res.Rows = append(res.Rows, &calculatorpb.MatrixRow{Values: row})
+		}
+		return &calculatorpb.Job{Result: &calculatorpb.Job_Matrix{Matrix: res}}, nil
+
+	default:
+		return nil, grpcerr.InvalidArgument("job", "Must be set")
+	}
+}
+
+func (s *Server) SubmitJob(ctx context.Context, req *calculatorpb.SubmitJobRequest) (*calculatorpb.Job, error) {
+	if err := checkJob(req); err != nil {
+		return nil, err
+	}
+	return s.jobs.submit(req)
+}
+
+func (s *Server) GetJob(ctx context.Context, req *calculatorpb.GetJobRequest) (*calculatorpb.Job, error) {
+	_, info, err := s.jobs.get(req.GetId())
+	return info, err
+}
+
+func (s *Server) CancelJob(ctx context.Context, req *calculatorpb.CancelJobRequest) (*calculatorpb.Job, error) {
+	return s.jobs.cancel(req.GetId())
+}
+
+func (s *Server) WaitJob(ctx context.Context, req *calculatorpb.WaitJobRequest) (*calculatorpb.Job, error) {
+	var timeout time.Duration
+	if req.GetTimeout() != nil {
+		if err := req.GetTimeout().CheckValid(); err != nil || req.GetTimeout().AsDuration() < 0 {
+			return nil, grpcerr.InvalidArgument("timeout", "Must be a positive duration")
+		}
+		timeout = req.GetTimeout().AsDuration()
+	}
+	return s.jobs.wait(ctx, req.GetId(), timeout)
+}
+
+func (s *Server) ListJobs(ctx context.Context, req *calculatorpb.ListJobsRequest) (*calculatorpb.ListJobsResponse, error) {
+	return s.jobs.list(req)
+}
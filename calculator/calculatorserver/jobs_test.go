@@ -0,0 +1,319 @@
+package calculatorserver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// factorJob is a job identified by its number.
+func factorJob(n int64) *calculatorpb.SubmitJobRequest {
+	return &calculatorpb.SubmitJobRequest{
+		Job: &calculatorpb.SubmitJobRequest_PrimeNumberDecomposition{
+			PrimeNumberDecomposition: &calculatorpb.PrimeNumberDecompositionRequest{Number: n},
+		},
+	}
+}
+
+func jobNumber(req *calculatorpb.SubmitJobRequest) int64 {
+	return req.GetPrimeNumberDecomposition().GetNumber()
+}
+
+// blockingExecutor succeeds at once for the numbers of done, and runs
+// until canceled for the others.
+func blockingExecutor(done ...int64) jobExecutor {
+	return func(ctx context.Context, req *calculatorpb.SubmitJobRequest, progress progressFunc) (*calculatorpb.Job, error) {
+		for _, n := range done {
+			if jobNumber(req) == n {
+				return &calculatorpb.Job{}, nil
+			}
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+}
+
+// waitState waits until the job is in state.
+func waitState(t *testing.T, m *jobManager, id string, state calculatorpb.JobState) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, info, err := m.get(id); err == nil && info.GetState() == state {
+			return
+		}
+	}
+	t.Fatalf("job %s is not %v", id, state)
+}
+
+func TestJobManagerRestore(t *testing.T) {
+	tests := []struct {
+		name   string
+		number int64
+		cancel bool
+		// before is the state of the job when the server stops
+		before calculatorpb.JobState
+		after  calculatorpb.JobState
+		// runs is the number of times the job runs after the restart
+		runs int
+	}{
+		{name: "succeeded", number: 2, before: calculatorpb.JobState_SUCCEEDED, after: calculatorpb.JobState_SUCCEEDED},
+		{name: "running", number: 3, before: calculatorpb.JobState_RUNNING, after: calculatorpb.JobState_SUCCEEDED, runs: 1},
+		{name: "queued", number: 5, before: calculatorpb.JobState_QUEUED, after: calculatorpb.JobState_SUCCEEDED, runs: 1},
+		{name: "canceled", number: 7, cancel: true, before: calculatorpb.JobState_CANCELED, after: calculatorpb.JobState_CANCELED},
+	}
+
+	dir := t.TempDir()
+	// one worker, blocked by the running job: the next ones stay queued
+	m, err := newJobManager(JobOptions{Workers: 1, Dir: dir}, blockingExecutor(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(tests))
+	for i, tt := range tests {
+		info, err := m.submit(factorJob(tt.number))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = info.GetId()
+		if tt.cancel {
+			if _, err := m.cancel(ids[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		waitState(t, m, ids[i], tt.before)
+	}
+
+	// a new manager on the same directory, as after a restart
+	var mu sync.Mutex
+	runs := map[int64]int{}
+	restarted, err := newJobManager(JobOptions{Workers: 1, Dir: dir}, func(ctx context.Context, req *calculatorpb.SubmitJobRequest, progress progressFunc) (*calculatorpb.Job, error) {
+		mu.Lock()
+		runs[jobNumber(req)]++
+		mu.Unlock()
+		return &calculatorpb.Job{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := restarted.wait(context.Background(), ids[i], 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.GetState() != tt.after {
+				t.Errorf("state = %v, want %v", info.GetState(), tt.after)
+			}
+			if jobNumber(info.GetRequest()) != tt.number {
+				t.Errorf("request = %v, want the number %d", info.GetRequest(), tt.number)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if runs[tt.number] != tt.runs {
+				t.Errorf("run %d times after the restart, want %d", runs[tt.number], tt.runs)
+			}
+		})
+	}
+}
+
+func TestJobManager(t *testing.T) {
+	tests := []struct {
+		name string
+		// run submits the jobs and returns the error of the last call
+		run      func(t *testing.T, m *jobManager) error
+		wantCode codes.Code
+	}{
+		{
+			name: "queue full",
+			run: func(t *testing.T, m *jobManager) error {
+				// the first job runs, the second one fills the queue
+				info, err := m.submit(factorJob(1))
+				if err != nil {
+					return err
+				}
+				waitState(t, m, info.GetId(), calculatorpb.JobState_RUNNING)
+				if _, err := m.submit(factorJob(1)); err != nil {
+					return err
+				}
+				_, err = m.submit(factorJob(1))
+				return err
+			},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name: "cancel a running job",
+			run: func(t *testing.T, m *jobManager) error {
+				info, err := m.submit(factorJob(1))
+				if err != nil {
+					return err
+				}
+				waitState(t, m, info.GetId(), calculatorpb.JobState_RUNNING)
+				if _, err := m.cancel(info.GetId()); err != nil {
+					return err
+				}
+				waitState(t, m, info.GetId(), calculatorpb.JobState_CANCELED)
+				return nil
+			},
+		},
+		{
+			name: "cancel a job done",
+			run: func(t *testing.T, m *jobManager) error {
+				info, err := m.submit(factorJob(2))
+				if err != nil {
+					return err
+				}
+				waitState(t, m, info.GetId(), calculatorpb.JobState_SUCCEEDED)
+				_, err = m.cancel(info.GetId())
+				return err
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name: "wait timeout",
+			run: func(t *testing.T, m *jobManager) error {
+				info, err := m.submit(factorJob(1))
+				if err != nil {
+					return err
+				}
+				info, err = m.wait(context.Background(), info.GetId(), 10*time.Millisecond)
+				if err == nil && info.GetState() == calculatorpb.JobState_SUCCEEDED {
+					t.Errorf("state = %v after the timeout", info.GetState())
+				}
+				return err
+			},
+		},
+		{
+			name: "unknown job",
+			run: func(t *testing.T, m *jobManager) error {
+				_, err := m.wait(context.Background(), "unknown", 0)
+				return err
+			},
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newJobManager(JobOptions{Workers: 1, QueueSize: 1}, blockingExecutor(2))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.run(t, m); status.Code(err) != tt.wantCode {
+				t.Errorf("error = %v, want code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestJobManagerMaxJobs(t *testing.T) {
+	dir := t.TempDir()
+	// the jobs of even numbers succeed, the others run until canceled
+	m, err := newJobManager(JobOptions{Workers: 2, Dir: dir, MaxJobs: 3}, blockingExecutor(2, 4, 6, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ids are the jobs by number
+	ids := map[int64]string{}
+	submit := func(n int64, state calculatorpb.JobState) {
+		t.Helper()
+		info, err := m.submit(factorJob(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[n] = info.GetId()
+		waitState(t, m, ids[n], state)
+	}
+	submit(1, calculatorpb.JobState_RUNNING)
+	submit(2, calculatorpb.JobState_SUCCEEDED)
+	submit(4, calculatorpb.JobState_SUCCEEDED)
+	// the job done first is removed, not the running one
+	submit(6, calculatorpb.JobState_SUCCEEDED)
+	submit(8, calculatorpb.JobState_SUCCEEDED)
+
+	for n, kept := range map[int64]bool{1: true, 2: false, 4: false, 6: true, 8: true} {
+		_, _, err := m.get(ids[n])
+		if kept && err != nil || !kept && status.Code(err) != codes.NotFound {
+			t.Errorf("job %d: error = %v, kept %v", n, err, kept)
+		}
+		if _, err := os.Stat(filepath.Join(dir, ids[n]+".json")); kept != (err == nil) {
+			t.Errorf("job %d: file error = %v, kept %v", n, err, kept)
+		}
+	}
+}
+
+func TestJobManagerList(t *testing.T) {
+	m, err := newJobManager(JobOptions{Workers: 1}, blockingExecutor(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, n := range []int64{2, 2, 2, 1, 3} {
+		info, err := m.submit(factorJob(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, info.GetId())
+		if n == 2 {
+			waitState(t, m, info.GetId(), calculatorpb.JobState_SUCCEEDED)
+		}
+		// distinct creation times, so that the order is known
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		name     string
+		pageSize int32
+		state    calculatorpb.JobState
+		// want are the indexes of the jobs listed, page by page
+		want [][]int
+	}{
+		{name: "one page", want: [][]int{{4, 3, 2, 1, 0}}},
+		{name: "pages", pageSize: 2, want: [][]int{{4, 3}, {2, 1}, {0}}},
+		{name: "exact pages", pageSize: 5, want: [][]int{{4, 3, 2, 1, 0}}},
+		{name: "state", pageSize: 2, state: calculatorpb.JobState_SUCCEEDED, want: [][]int{{2, 1}, {0}}},
+		{name: "queued", state: calculatorpb.JobState_QUEUED, want: [][]int{{4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &calculatorpb.ListJobsRequest{PageSize: tt.pageSize, State: tt.state}
+			for page, want := range tt.want {
+				res, err := m.list(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(res.GetJobs()) != len(want) {
+					t.Fatalf("page %d: %d jobs, want %d", page, len(res.GetJobs()), len(want))
+				}
+				for i, info := range res.GetJobs() {
+					if info.GetId() != ids[want[i]] {
+						t.Errorf("page %d: job %d is %s, want %s", page, i, info.GetId(), ids[want[i]])
+					}
+					if info.GetRequest() != nil {
+						t.Errorf("page %d: job %d has its request", page, i)
+					}
+				}
+				if last := page == len(tt.want)-1; last != (res.GetNextPageToken() == "") {
+					t.Fatalf("page %d: next page token %q", page, res.GetNextPageToken())
+				}
+				req.PageToken = res.GetNextPageToken()
+			}
+		})
+	}
+
+	// the jobs listed are copies, the manager keeps their request
+	if _, info, err := m.get(ids[0]); err != nil || jobNumber(info.GetRequest()) != 2 {
+		t.Errorf("get() = %v, %v, want the request", info, err)
+	}
+	if _, err := m.list(&calculatorpb.ListJobsRequest{PageToken: "!"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("list() with an invalid token = %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,345 @@
+package calculatorserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// maxMatrixCells bounds the number of values of a matrix.
+	maxMatrixCells = 1000000
+	// maxMatrixCost bounds the number of multiplications of an operation.
+	maxMatrixCost = 1e9
+)
+
+type matrix [][]float64
+
+func (m matrix) columns() int {
+	if len(m) == 0 {
+		return 0
+	}
+	return len(m[0])
+}
+
+func (m matrix) clone() matrix {
+	c := make(matrix, len(m))
+	for i, row := range m {
+		c[i] = append([]float64(nil), row...)
+	}
+	return c
+}
+
+func identity(n int) matrix {
+	m := make(matrix, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		m[i][i] = 1
+	}
+	return m
+}
+
+// appendRow appends a row received for the operand name to m.
+func appendRow(m matrix, name string, values []float64) (matrix, error) {
+	if len(values) == 0 {
+		return nil, grpcerr.InvalidArgument(name, fmt.Sprintf("Row %d is empty", len(m)))
+	}
+	if len(m) > 0 && len(values) != m.columns() {
+		return nil, grpcerr.InvalidArgument(name, fmt.Sprintf("Row %d has %d values, the previous rows %d", len(m), len(values), m.columns()))
+	}
+	if (len(m)+1)*len(values) > maxMatrixCells {
+		return nil, grpcerr.InvalidArgument(name, fmt.Sprintf("Must have at most %d values", maxMatrixCells))
+	}
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, grpcerr.InvalidArgument(name, fmt.Sprintf("Row %d: must be finite numbers, received %v", len(m), v))
+		}
+	}
+
+	return append(m, values), nil
+}
+
+// checkSquare checks that a is a square matrix, and that the cost of the
+// elimination of a with extra columns fits maxMatrixCost.
+func checkSquare(a matrix, extra int) error {
+	n := len(a)
+	if a.columns() != n {
+		return grpcerr.InvalidArgument("a", fmt.Sprintf("Must be a square matrix, received %d x %d", n, a.columns()))
+	}
+	if float64(n)*float64(n)*(float64(n)/3+float64(extra)) > maxMatrixCost {
+		return grpcerr.InvalidArgument("a", "The matrix is too large for this operation")
+	}
+	return nil
+}
+
+// pivot returns the row, from k, of the largest value of column k.
+func pivot(m matrix, k int) int {
+	p := k
+	for i := k + 1; i < len(m); i++ {
+		if math.Abs(m[i][k]) > math.Abs(m[p][k]) {
+			p = i
+		}
+	}
+	return p
+}
+
+// determinant computes the determinant of a with a LU decomposition with
+// partial pivoting.
+func determinant(ctx context.Context, a matrix, progress progressFunc) (float64, error) {
+	m := a.clone()
+	n := len(m)
+	det := 1.0
+
+	for k := 0; k < n; k++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		progress.report(float64(k) / float64(n))
+
+		p := pivot(m, k)
+		if m[p][k] == 0 {
+			return 0, nil
+		}
+		if p != k {
+			m[p], m[k] = m[k], m[p]
+			det = -det
+		}
+		det *= m[k][k]
+
+		for i := k + 1; i < n; i++ {
+			f := m[i][k] / m[k][k]
+			for j := k + 1; j < n; j++ {
+				m[i][j] -= f * m[k][j]
+			}
+		}
+	}
+	return det, nil
+}
+
+// solve returns x such that a * x = b with a Gaussian elimination with
+// partial pivoting. The matrix is singular when a pivot is negligible
+// compared to the largest value of a.
+func solve(ctx context.Context, a, b matrix, progress progressFunc) (matrix, error) {
+	m := a.clone()
+	x := b.clone()
+	n := len(m)
+
+	var scale float64
+	for _, row := range m {
+		for _, v := range row {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	tolerance := float64(n) * scale * 0x1p-52
+
+	for k := 0; k < n; k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		progress.report(float64(k) / float64(n))
+
+		p := pivot(m, k)
+		if math.Abs(m[p][k]) <= tolerance {
+			return nil, grpcerr.InvalidArgument("a", "The matrix is singular")
+		}
+		m[p], m[k] = m[k], m[p]
+		x[p], x[k] = x[k], x[p]
+
+		for i := k + 1; i < n; i++ {
+			f := m[i][k] / m[k][k]
+			for j := k + 1; j < n; j++ {
+				m[i][j] -= f * m[k][j]
+			}
+			for j := range x[i] {
+				x[i][j] -= f * x[k][j]
+			}
+		}
+	}
+
+	for k := n - 1; k >= 0; k-- {
+		for i := k + 1; i < n; i++ {
+			for j := range x[k] {
+				x[k][j] -= m[k][i] * x[i][j]
+			}
+		}
+		for j := range x[k] {
+			x[k][j] /= m[k][k]
+		}
+	}
+	return x, nil
+}
+
+func (m matrix) finite() bool {
+	for _, row := range m {
+		for _, v := range row {
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (*Server) ComputeMatrix(stream calculatorpb.CalculatorService_ComputeMatrixServer) error {
+	var operation calculatorpb.MatrixOperation
+	var a, b matrix
+	first := true
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if first {
+			operation = req.GetOperation()
+			first = false
+		}
+		switch row := req.GetRow().(type) {
+		case *calculatorpb.MatrixRequest_A:
+			if len(b) > 0 {
+				return grpcerr.InvalidArgument("a", "The rows of a must be sent before those of b")
+			}
+			a, err = appendRow(a, "a", row.A.GetValues())
+		case *calculatorpb.MatrixRequest_B:
+			b, err = appendRow(b, "b", row.B.GetValues())
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := checkOperands(operation, a, b); err != nil {
+		return err
+	}
+	x, det, err := computeMatrix(stream.Context(), operation, a, b, nil)
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return status.FromContextError(err).Err()
+	} else if err != nil {
+		return err
+	}
+
+	if operation == calculatorpb.MatrixOperation_MATRIX_DETERMINANT {
+		return stream.Send(&calculatorpb.MatrixResponse{
+			Result: &calculatorpb.MatrixResponse_Determinant{Determinant: det},
+		})
+	}
+	for _, row := range x {
+		if err := stream.Send(&calculatorpb.MatrixResponse{
+			Result: &calculatorpb.MatrixResponse_Row{
+				Row: &calculatorpb.MatrixRow{Values: row},
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkOperands checks that a, and b when it is needed, are set for
+// operation.
+func checkOperands(operation calculatorpb.MatrixOperation, a, b matrix) error {
+	if operation == calculatorpb.MatrixOperation_MATRIX_OPERATION_UNSPECIFIED {
+		return grpcerr.InvalidArgument("operation", "Must be set in the first request")
+	}
+	if len(a) == 0 {
+		return grpcerr.InvalidArgument("a", "No row received")
+	}
+	needsB := operation == calculatorpb.MatrixOperation_MATRIX_MULTIPLY || operation == calculatorpb.MatrixOperation_MATRIX_SOLVE
+	if needsB && len(b) == 0 {
+		return grpcerr.InvalidArgument("b", fmt.Sprintf("No row received, required by %v", operation))
+	} else if !needsB && len(b) > 0 {
+		return grpcerr.InvalidArgument("b", fmt.Sprintf("Not used by %v", operation))
+	}
+	return nil
+}
+
+// computeMatrix returns the result of operation: a matrix, or the
+// determinant for MATRIX_DETERMINANT.
+func computeMatrix(ctx context.Context, operation calculatorpb.MatrixOperation, a, b matrix, progress progressFunc) (matrix, float64, error) {
+	var x matrix
+
+	switch operation {
+	case calculatorpb.MatrixOperation_MATRIX_MULTIPLY:
+		if a.columns() != len(b) {
+			return nil, 0, grpcerr.InvalidArgument("b", fmt.Sprintf("Has %d rows, a has %d columns", len(b), a.columns()))
+		}
+		if float64(len(a))*float64(len(b))*float64(b.columns()) > maxMatrixCost {
+			return nil, 0, grpcerr.InvalidArgument("a", "The matrices are too large to be multiplied")
+		}
+
+		x = make(matrix, len(a))
+		for i, row := range a {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+			progress.report(float64(i) / float64(len(a)))
+
+			x[i] = make([]float64, b.columns())
+			for k, v := range row {
+				for j, w := range b[k] {
+					x[i][j] += v * w
+				}
+			}
+		}
+
+	case calculatorpb.MatrixOperation_MATRIX_TRANSPOSE:
+		x = make(matrix, a.columns())
+		for j := range x {
+			x[j] = make([]float64, len(a))
+			for i, row := range a {
+				x[j][i] = row[j]
+			}
+		}
+
+	case calculatorpb.MatrixOperation_MATRIX_DETERMINANT:
+		if err := checkSquare(a, 0); err != nil {
+			return nil, 0, err
+		}
+		det, err := determinant(ctx, a, progress)
+		if err != nil {
+			return nil, 0, err
+		}
+		if math.IsInf(det, 0) {
+			return nil, 0, grpcerr.OutOfRange("determinant", "The determinant overflows a double")
+		}
+		return nil, det, nil
+
+	case calculatorpb.MatrixOperation_MATRIX_INVERSE, calculatorpb.MatrixOperation_MATRIX_SOLVE:
+		// the inverse solves a * x = identity, only allocated once a is
+		// known to be a square matrix of an acceptable size
+		extra := len(a)
+		if operation == calculatorpb.MatrixOperation_MATRIX_SOLVE {
+			if len(b) != len(a) {
+				return nil, 0, grpcerr.InvalidArgument("b", fmt.Sprintf("Has %d rows, a has %d", len(b), len(a)))
+			}
+			extra = b.columns()
+		}
+		if err := checkSquare(a, extra); err != nil {
+			return nil, 0, err
+		}
+		if operation == calculatorpb.MatrixOperation_MATRIX_INVERSE {
+			b = identity(len(a))
+		}
+
+		var err error
+		if x, err = solve(ctx, a, b, progress); err != nil {
+			return nil, 0, err
+		}
+
+	default:
+		return nil, 0, grpcerr.InvalidArgument("operation", fmt.Sprintf("Unknown operation %v", operation))
+	}
+
+	if !x.finite() {
+		return nil, 0, grpcerr.OutOfRange("row", "The result overflows a double")
+	}
+	return x, 0, nil
+}
@@ -0,0 +1,206 @@
+package calculatorserver
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestComputeMatrix(t *testing.T) {
+	a := matrix{{2, 1, -1}, {-3, -1, 2}, {-2, 1, 2}}
+	singular := matrix{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+
+	tests := []struct {
+		name      string
+		operation calculatorpb.MatrixOperation
+		a, b      matrix
+		want      matrix
+		wantDet   float64
+		wantCode  codes.Code
+	}{
+		{
+			name:      "multiply",
+			operation: calculatorpb.MatrixOperation_MATRIX_MULTIPLY,
+			a:         matrix{{1, 2, 3}, {4, 5, 6}},
+			b:         matrix{{1}, {0}, {-1}},
+			want:      matrix{{-2}, {-2}},
+		},
+		{
+			name:      "multiply mismatched",
+			operation: calculatorpb.MatrixOperation_MATRIX_MULTIPLY,
+			a:         matrix{{1, 2}},
+			b:         matrix{{1, 2}},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "transpose",
+			operation: calculatorpb.MatrixOperation_MATRIX_TRANSPOSE,
+			a:         matrix{{1, 2, 3}, {4, 5, 6}},
+			want:      matrix{{1, 4}, {2, 5}, {3, 6}},
+		},
+		{
+			name:      "determinant",
+			operation: calculatorpb.MatrixOperation_MATRIX_DETERMINANT,
+			a:         a,
+			wantDet:   -1,
+		},
+		{
+			name:      "determinant of one value",
+			operation: calculatorpb.MatrixOperation_MATRIX_DETERMINANT,
+			a:         matrix{{5}},
+			wantDet:   5,
+		},
+		{
+			name:      "determinant of a singular matrix",
+			operation: calculatorpb.MatrixOperation_MATRIX_DETERMINANT,
+			a:         matrix{{1, 2}, {2, 4}},
+			wantDet:   0,
+		},
+		{
+			name:      "determinant overflow",
+			operation: calculatorpb.MatrixOperation_MATRIX_DETERMINANT,
+			a:         matrix{{1e200, 0}, {0, 1e200}},
+			wantCode:  codes.OutOfRange,
+		},
+		{
+			name:      "determinant of a non square matrix",
+			operation: calculatorpb.MatrixOperation_MATRIX_DETERMINANT,
+			a:         matrix{{1, 2, 3}, {4, 5, 6}},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "inverse",
+			operation: calculatorpb.MatrixOperation_MATRIX_INVERSE,
+			a:         matrix{{4, 7}, {2, 6}},
+			want:      matrix{{0.6, -0.7}, {-0.2, 0.4}},
+		},
+		{
+			name:      "inverse of a singular matrix",
+			operation: calculatorpb.MatrixOperation_MATRIX_INVERSE,
+			a:         singular,
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "inverse of a non square matrix",
+			operation: calculatorpb.MatrixOperation_MATRIX_INVERSE,
+			a:         tallMatrix(maxMatrixCells),
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "solve",
+			operation: calculatorpb.MatrixOperation_MATRIX_SOLVE,
+			a:         a,
+			b:         matrix{{8}, {-11}, {-3}},
+			want:      matrix{{2}, {3}, {-1}},
+		},
+		{
+			name:      "solve with a singular matrix",
+			operation: calculatorpb.MatrixOperation_MATRIX_SOLVE,
+			a:         singular,
+			b:         matrix{{1}, {2}, {3}},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "solve with a zero matrix",
+			operation: calculatorpb.MatrixOperation_MATRIX_SOLVE,
+			a:         matrix{{0, 0}, {0, 0}},
+			b:         matrix{{1}, {2}},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:      "solve mismatched",
+			operation: calculatorpb.MatrixOperation_MATRIX_SOLVE,
+			a:         a,
+			b:         matrix{{1}, {2}},
+			wantCode:  codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkOperands(tt.operation, tt.a, tt.b); err != nil {
+				t.Fatalf("checkOperands() = %v", err)
+			}
+			x, det, err := computeMatrix(context.Background(), tt.operation, tt.a, tt.b, nil)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+
+			if math.Abs(det-tt.wantDet) > 1e-9 {
+				t.Errorf("determinant = %v, want %v", det, tt.wantDet)
+			}
+			if !equalMatrices(x, tt.want) {
+				t.Errorf("result = %v, want %v", x, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOperands(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation calculatorpb.MatrixOperation
+		a, b      matrix
+		wantCode  codes.Code
+	}{
+		{name: "no operation", a: matrix{{1}}, wantCode: codes.InvalidArgument},
+		{name: "no a", operation: calculatorpb.MatrixOperation_MATRIX_TRANSPOSE, wantCode: codes.InvalidArgument},
+		{name: "no b", operation: calculatorpb.MatrixOperation_MATRIX_MULTIPLY, a: matrix{{1}}, wantCode: codes.InvalidArgument},
+		{name: "unused b", operation: calculatorpb.MatrixOperation_MATRIX_INVERSE, a: matrix{{1}}, b: matrix{{1}}, wantCode: codes.InvalidArgument},
+		{name: "valid", operation: calculatorpb.MatrixOperation_MATRIX_SOLVE, a: matrix{{1}}, b: matrix{{1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkOperands(tt.operation, tt.a, tt.b)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("checkOperands() = %v, want code %v", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestComputeMatrixCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := computeMatrix(ctx, calculatorpb.MatrixOperation_MATRIX_INVERSE, matrix{{1, 0}, {0, 1}}, nil, nil)
+	if err != context.Canceled {
+		t.Errorf("computeMatrix() = %v, want %v", err, context.Canceled)
+	}
+}
+
+// tallMatrix returns a matrix of n rows of one value, whose inverse must
+// be rejected before allocating the n x n identity.
+func tallMatrix(n int) matrix {
+	m := make(matrix, n)
+	values := []float64{1}
+	for i := range m {
+		m[i] = values
+	}
+	return m
+}
+
+func equalMatrices(x, y matrix) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if len(x[i]) != len(y[i]) {
+			return false
+		}
+		for j := range x[i] {
+			if math.Abs(x[i][j]-y[i][j]) > 1e-9 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,249 @@
+// Package calculatorserver implements the CalculatorService.
+package calculatorserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb/calculatorpbconnect"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"github.com/pjserol/tuto-grpc-go/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Server implements calculatorpb.CalculatorServiceServer.
+type Server struct {
+	rates          *currencyRates
+	factorizations *resultCache[[]primePower]
+	bigResults     *resultCache[*calculatorpb.BigCalculateResponse]
+	jobs           *jobManager
+}
+
+// Options configures the Server.
+type Options struct {
+	// RatesPath is the currency rates file, reloaded when it is modified.
+	// The currency conversions fail when it is empty.
+	RatesPath string
+	// RatesMaxAge is the age of the date of the rates after which the
+	// currency conversions fail, no limit when zero.
+	RatesMaxAge time.Duration
+	// Cache configures the caches of the results.
+	Cache CacheConfig
+	// Jobs configures the jobs.
+	Jobs JobOptions
+}
+
+// OptionsFromEnv reads the Options from the CURRENCY_RATES,
+// CURRENCY_RATES_MAX_AGE, CACHE_CONFIG and JOB_* environment variables,
+// with DefaultCacheConfig when CACHE_CONFIG is not set.
+func OptionsFromEnv() (Options, error) {
+	jobs, err := jobOptionsFromEnv()
+	if err != nil {
+		return Options{}, err
+	}
+	opts := Options{
+		RatesPath: os.Getenv("CURRENCY_RATES"),
+		Cache:     DefaultCacheConfig,
+		Jobs:      jobs,
+	}
+
+	if v := os.Getenv("CURRENCY_RATES_MAX_AGE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return Options{}, fmt.Errorf("Invalid CURRENCY_RATES_MAX_AGE %q: %v", v, err)
+		}
+		opts.RatesMaxAge = d
+	}
+	if path := os.Getenv("CACHE_CONFIG"); path != "" {
+		cfg, err := LoadCacheConfig(path)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Cache = cfg
+	}
+	return opts, nil
+}
+
+// New returns a Server configured by opts, whose job workers are started.
+func New(opts Options) (*Server, error) {
+	s := &Server{
+		rates:          newCurrencyRates(opts.RatesPath, opts.RatesMaxAge),
+		factorizations: newResultCache[[]primePower](calculatorpbconnect.CalculatorServicePrimeNumberDecompositionProcedure, opts.Cache),
+		bigResults:     newResultCache[*calculatorpb.BigCalculateResponse](calculatorpbconnect.CalculatorServiceBigCalculateProcedure, opts.Cache),
+	}
+
+	jobs, err := newJobManager(opts.Jobs, s.executeJob)
+	if err != nil {
+		return nil, err
+	}
+	s.jobs = jobs
+	return s, nil
+}
+
+// Service returns the CalculatorService, to be run by the server package,
+// configured by the environment variables of OptionsFromEnv.
+func Service() (server.Service, error) {
+	opts, err := OptionsFromEnv()
+	if err != nil {
+		return server.Service{}, err
+	}
+	srv, err := New(opts)
+	if err != nil {
+		return server.Service{}, err
+	}
+
+	return server.Service{
+		Name: "calculator",
+		Register: func(s *grpc.Server) {
+			calculatorpb.RegisterCalculatorServiceServer(s, srv)
+		},
+		Connect: calculatorpbconnect.NewCalculatorServiceProxyHandler,
+	}, nil
+}
+
+func (*Server) Sum(ctx context.Context, req *calculatorpb.SumRequest) (*calculatorpb.SumResponse, error) {
+	sum := int64(req.FirstNumber) + int64(req.SecondNumber)
+	if sum > math.MaxInt32 || sum < math.MinInt32 {
+		return nil, grpcerr.OutOfRange("sum_result", "The sum overflows int32, use BigCalculate")
+	}
+
+	return &calculatorpb.SumResponse{
+		SumResult: int32(sum),
+	}, nil
+}
+
+func (s *Server) PrimeNumberDecomposition(req *calculatorpb.PrimeNumberDecompositionRequest, stream calculatorpb.CalculatorService_PrimeNumberDecompositionServer) error {
+	n, err := decompositionNumber(req)
+	if err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	factors, err := s.factorizations.get(ctx, n.String(), func(ctx context.Context) ([]primePower, error) {
+		return factorize(ctx, n, nil)
+	})
+	if err != nil {
+		// the client canceled the call, or its deadline is exceeded
+		return status.FromContextError(err).Err()
+	}
+
+	for _, f := range factors {
+		if err := stream.Send(f.response()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decompositionNumber returns the number to factorize of req.
+func decompositionNumber(req *calculatorpb.PrimeNumberDecompositionRequest) (*big.Int, error) {
+	n := big.NewInt(req.GetNumber())
+	if req.GetBigNumber() != "" {
+		if req.GetNumber() != 0 {
+			return nil, grpcerr.InvalidArgument("big_number", "Cannot be set with number")
+		}
+		n.SetString(req.GetBigNumber(), 10)
+	}
+	if n.Sign() <= 0 {
+		return nil, grpcerr.InvalidArgument("number", "must be greater than or equal to 1")
+	}
+	return n, nil
+}
+
+func (*Server) ComputeAverage(stream calculatorpb.CalculatorService_ComputeAverageServer) error {
+	var sum int64
+	count := 0
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			if count == 0 {
+				return grpcerr.InvalidArgument("number", "No number received, the average of an empty stream is undefined")
+			}
+			return stream.SendAndClose(&calculatorpb.ComputeAverageResponse{
+				Average: float64(sum) / float64(count),
+			})
+		} else if err != nil {
+			return err
+		}
+
+		sum += int64(req.GetNumber())
+		count++
+	}
+}
+
+func (*Server) FindMaximum(stream calculatorpb.CalculatorService_FindMaximumServer) error {
+	var max int32
+	received := false
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		number := req.GetNumber()
+		if !received || number > max {
+			max = number
+			received = true
+			if err := stream.Send(&calculatorpb.FindMaximumResponse{
+				Maximum: max,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (*Server) SquareRoot(ctx context.Context, req *calculatorpb.SquareRootRequest) (*calculatorpb.SquareRootResponse, error) {
+	number := req.GetNumber()
+
+	if number < 0 {
+		return nil, grpcerr.InvalidArgument("number", fmt.Sprintf("Received a negative number: %d", number))
+	}
+
+	return &calculatorpb.SquareRootResponse{
+		NumberRoot: math.Sqrt(float64(number)),
+	}, nil
+}
+
+func (s *Server) BigCalculate(ctx context.Context, req *calculatorpb.BigCalculateRequest) (*calculatorpb.BigCalculateResponse, error) {
+	key, ok := bigCalculateKey(req)
+	if !ok {
+		return bigCalculate(req)
+	}
+
+	res, err := s.bigResults.get(ctx, key, func(context.Context) (*calculatorpb.BigCalculateResponse, error) {
+		return bigCalculate(req)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the cached response is shared with the other calls
+	return proto.Clone(res).(*calculatorpb.BigCalculateResponse), nil
+}
+
+func (*Server) Evaluate(ctx context.Context, req *calculatorpb.EvaluateRequest) (*calculatorpb.EvaluateResponse, error) {
+	result, err := evaluate(req.GetExpression(), req.GetVariables())
+	if err != nil {
+		return nil, err
+	}
+
+	return &calculatorpb.EvaluateResponse{
+		Result: result,
+	}, nil
+}
+
+func (s *Server) Convert(ctx context.Context, req *calculatorpb.ConvertRequest) (*calculatorpb.ConvertResponse, error) {
+	return s.convert(req)
+}
@@ -0,0 +1,292 @@
+package calculatorserver
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+)
+
+// defaultPercentiles are computed when the first request lists none.
+var defaultPercentiles = []float64{50, 90, 99}
+
+// defaultEmitEvery is the number of values between two RunningStatistics
+// responses when the first request does not set it.
+const defaultEmitEvery = 10
+
+// compression bounds the number of centroids of the t-digest, about
+// 2 * compression: the larger, the more accurate and the more memory.
+const compression = 100
+
+// statistics accumulates the values of a stream in constant memory: the
+// sum with Neumaier's compensated summation, the mean and the variance with
+// Welford's algorithm, and the quantiles with a t-digest.
+type statistics struct {
+	percentiles []float64
+
+	count         int64
+	sum, sumError float64
+	mean, m2      float64
+	min, max      float64
+	digest        tdigest
+}
+
+// newStatistics reads the percentiles to compute from the first request.
+func newStatistics(req *calculatorpb.ComputeStatisticsRequest) (*statistics, error) {
+	percentiles := req.GetPercentiles()
+	if len(percentiles) == 0 {
+		percentiles = defaultPercentiles
+	}
+	for _, p := range percentiles {
+		if !(p >= 0 && p <= 100) {
+			return nil, grpcerr.InvalidArgument("percentiles", fmt.Sprintf("Must be between 0 and 100, received %v", p))
+		}
+	}
+
+	return &statistics{percentiles: percentiles}, nil
+}
+
+// finite reports whether v is neither infinite nor NaN.
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// checkValue rejects the values that are not finite.
+func checkValue(v float64) error {
+	if !finite(v) {
+		return grpcerr.InvalidArgument("value", fmt.Sprintf("Must be a finite number, received %v", v))
+	}
+	return nil
+}
+
+func (s *statistics) add(v float64) error {
+	if err := checkValue(v); err != nil {
+		return err
+	}
+
+	s.count++
+	if s.count == 1 || v < s.min {
+		s.min = v
+	}
+	if s.count == 1 || v > s.max {
+		s.max = v
+	}
+
+	t := s.sum + v
+	if math.Abs(s.sum) >= math.Abs(v) {
+		s.sumError += (s.sum - t) + v
+	} else {
+		s.sumError += (v - t) + s.sum
+	}
+	s.sum = t
+
+	delta := v - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (v - s.mean)
+
+	s.digest.add(v)
+	return nil
+}
+
+func (s *statistics) response() (*calculatorpb.ComputeStatisticsResponse, error) {
+	res := &calculatorpb.ComputeStatisticsResponse{
+		Count: s.count,
+	}
+	if s.count == 0 {
+		return res, nil
+	}
+
+	// once the sum overflows, the compensation is infinite as well, and
+	// their sum NaN
+	res.Sum = s.sum + s.sumError
+	if !finite(s.sum) || !finite(res.Sum) {
+		return nil, grpcerr.OutOfRange("sum", "The sum of the values overflows a double")
+	}
+	// the mean of Welford's algorithm only overflows with the difference
+	// of two values, e.g. 1e308 and -1e308, whose variance overflows too
+	if !finite(s.mean) || !finite(s.m2) {
+		return nil, grpcerr.OutOfRange("variance", "The variance of the values overflows a double")
+	}
+	res.Mean = s.mean
+	res.Variance = s.m2 / float64(s.count)
+	res.Stddev = math.Sqrt(res.Variance)
+	res.Min = s.min
+	res.Max = s.max
+	res.Median = s.digest.quantile(0.5)
+	for _, p := range s.percentiles {
+		res.Percentiles = append(res.Percentiles, &calculatorpb.Percentile{
+			Percentile: p,
+			Value:      s.digest.quantile(p / 100),
+		})
+	}
+	return res, nil
+}
+
+type centroid struct {
+	mean, weight float64
+}
+
+// tdigest estimates the quantiles of a stream with a merging t-digest
+// (Dunning and Ertl, "Computing extremely accurate quantiles using
+// t-digests"): the values are buffered, then merged into centroids that are
+// small near the extremes and large around the median.
+type tdigest struct {
+	centroids []centroid
+	buffer    []centroid
+	min, max  float64
+	weight    float64
+}
+
+func (t *tdigest) add(v float64) {
+	if t.weight == 0 || v < t.min {
+		t.min = v
+	}
+	if t.weight == 0 || v > t.max {
+		t.max = v
+	}
+	t.weight++
+
+	t.buffer = append(t.buffer, centroid{mean: v, weight: 1})
+	if len(t.buffer) >= 5*compression {
+		t.merge()
+	}
+}
+
+// scale is the k1 scale function: two centroids are merged as long as they
+// span at most 1 in k.
+func scale(q float64) float64 {
+	return compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+func (t *tdigest) merge() {
+	if len(t.buffer) == 0 {
+		return
+	}
+
+	all := append(t.centroids, t.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, 2*compression)
+	current := all[0]
+	var before float64
+	for _, c := range all[1:] {
+		if scale((before+current.weight+c.weight)/t.weight)-scale(before/t.weight) <= 1 {
+			current.weight += c.weight
+			current.mean += (c.mean - current.mean) * c.weight / current.weight
+			continue
+		}
+		merged = append(merged, current)
+		before += current.weight
+		current = c
+	}
+
+	t.centroids = append(merged, current)
+	t.buffer = t.buffer[:0]
+}
+
+// quantile interpolates between the centers of the centroids, and between
+// the extreme centroids and the min and max.
+func (t *tdigest) quantile(q float64) float64 {
+	t.merge()
+	c := t.centroids
+	if len(c) == 0 {
+		return 0
+	}
+	if len(c) == 1 {
+		return c[0].mean
+	}
+
+	index := q * t.weight
+	if index < c[0].weight/2 {
+		return t.min + index/(c[0].weight/2)*(c[0].mean-t.min)
+	}
+
+	cumulative := c[0].weight / 2
+	for i := 0; i < len(c)-1; i++ {
+		step := (c[i].weight + c[i+1].weight) / 2
+		if cumulative+step > index {
+			return c[i].mean + (index-cumulative)/step*(c[i+1].mean-c[i].mean)
+		}
+		cumulative += step
+	}
+
+	last := c[len(c)-1]
+	return last.mean + math.Min((index-cumulative)/(last.weight/2), 1)*(t.max-last.mean)
+}
+
+func (*Server) ComputeStatistics(stream calculatorpb.CalculatorService_ComputeStatisticsServer) error {
+	var stats *statistics
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if stats == nil {
+			if stats, err = newStatistics(req); err != nil {
+				return err
+			}
+		}
+		if err := stats.add(req.GetValue()); err != nil {
+			return err
+		}
+	}
+
+	if stats == nil {
+		return stream.SendAndClose(&calculatorpb.ComputeStatisticsResponse{})
+	}
+	res, err := stats.response()
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(res)
+}
+
+func (*Server) RunningStatistics(stream calculatorpb.CalculatorService_RunningStatisticsServer) error {
+	var stats *statistics
+	var emitEvery int64 = defaultEmitEvery
+
+	send := func() error {
+		res, err := stats.response()
+		if err != nil {
+			return err
+		}
+		return stream.Send(res)
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			if stats != nil && stats.count%emitEvery != 0 {
+				return send()
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if stats == nil {
+			if stats, err = newStatistics(req); err != nil {
+				return err
+			}
+			if req.GetEmitEvery() > 0 {
+				emitEvery = int64(req.GetEmitEvery())
+			}
+		}
+		if err := stats.add(req.GetValue()); err != nil {
+			return err
+		}
+
+		if stats.count%emitEvery == 0 {
+			if err := send(); err != nil {
+				return err
+			}
+		}
+	}
+}
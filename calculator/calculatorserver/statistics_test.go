@@ -0,0 +1,210 @@
+package calculatorserver
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatistics(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		want     *calculatorpb.ComputeStatisticsResponse
+		wantCode codes.Code
+		// wantField is the field of an OutOfRange error
+		wantField string
+		// sumOnly checks the count and sum only
+		sumOnly bool
+	}{
+		{
+			name: "no value",
+			want: &calculatorpb.ComputeStatisticsResponse{},
+		},
+		{
+			name:   "one value",
+			values: []float64{3},
+			want:   &calculatorpb.ComputeStatisticsResponse{Count: 1, Sum: 3, Mean: 3, Min: 3, Max: 3, Median: 3},
+		},
+		{
+			name:   "values",
+			values: []float64{1, 2, 3, 4},
+			want: &calculatorpb.ComputeStatisticsResponse{
+				Count: 4, Sum: 10, Mean: 2.5, Variance: 1.25, Stddev: math.Sqrt(1.25), Min: 1, Max: 4, Median: 2.5,
+			},
+		},
+		{
+			name:    "compensated sum",
+			values:  []float64{1e16, 1, -1e16},
+			want:    &calculatorpb.ComputeStatisticsResponse{Count: 3, Sum: 1},
+			sumOnly: true,
+		},
+		{
+			name:      "sum overflow",
+			values:    []float64{1e308, 1e308},
+			wantCode:  codes.OutOfRange,
+			wantField: "sum",
+		},
+		{
+			name:      "sum overflow then back in range",
+			values:    []float64{1e308, 1e308, -1e308},
+			wantCode:  codes.OutOfRange,
+			wantField: "sum",
+		},
+		{
+			name:      "negative sum overflow",
+			values:    []float64{-1e308, -1e308},
+			wantCode:  codes.OutOfRange,
+			wantField: "sum",
+		},
+		{
+			name:      "variance overflow",
+			values:    []float64{1e308, -1e308},
+			wantCode:  codes.OutOfRange,
+			wantField: "variance",
+		},
+		{
+			name:      "variance overflow of small values",
+			values:    []float64{1e155, -1e155},
+			wantCode:  codes.OutOfRange,
+			wantField: "variance",
+		},
+		{
+			name:     "NaN",
+			values:   []float64{1, math.NaN()},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "infinity",
+			values:   []float64{math.Inf(1)},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newStatistics(&calculatorpb.ComputeStatisticsRequest{Percentiles: []float64{50}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range tt.values {
+				if err = s.add(v); err != nil {
+					break
+				}
+			}
+			var res *calculatorpb.ComputeStatisticsResponse
+			if err == nil {
+				res, err = s.response()
+			}
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if tt.wantField != "" {
+				if req, ok := grpcerr.BadRequest(err); !ok || req.GetFieldViolations()[0].GetField() != tt.wantField {
+					t.Errorf("BadRequest = %v, want the field %s", req, tt.wantField)
+				}
+			}
+			if err != nil {
+				return
+			}
+
+			if res.GetCount() != tt.want.GetCount() {
+				t.Errorf("count = %d, want %d", res.GetCount(), tt.want.GetCount())
+			}
+			got := []float64{res.GetSum(), res.GetMean(), res.GetVariance(), res.GetStddev(), res.GetMin(), res.GetMax(), res.GetMedian()}
+			want := []float64{tt.want.GetSum(), tt.want.GetMean(), tt.want.GetVariance(), tt.want.GetStddev(), tt.want.GetMin(), tt.want.GetMax(), tt.want.GetMedian()}
+			if tt.sumOnly {
+				got, want = got[:1], want[:1]
+			}
+			for i := range got {
+				if math.IsNaN(got[i]) || math.Abs(got[i]-want[i]) > 1e-9*math.Max(1, math.Abs(want[i])) {
+					t.Errorf("sum, mean, variance, stddev, min, max, median = %v, want %v", got, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestStatisticsPercentiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		values func(r *rand.Rand) float64
+	}{
+		{"uniform", func(r *rand.Rand) float64 { return r.Float64() }},
+		{"exponential", func(r *rand.Rand) float64 { return r.ExpFloat64() * 100 }},
+		{"normal", func(r *rand.Rand) float64 { return r.NormFloat64() }},
+	}
+	percentiles := []float64{0, 1, 10, 25, 50, 75, 90, 99, 99.9, 100}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newStatistics(&calculatorpb.ComputeStatisticsRequest{Percentiles: percentiles})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := rand.New(rand.NewSource(1))
+			values := make([]float64, 100000)
+			for i := range values {
+				values[i] = tt.values(r)
+				if err := s.add(values[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			res, err := s.response()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Float64s(values)
+
+			if len(s.digest.centroids) > 4*compression {
+				t.Errorf("%d centroids, want at most %d", len(s.digest.centroids), 4*compression)
+			}
+			for _, p := range res.GetPercentiles() {
+				// the error on the rank of the estimate, which is smaller
+				// near the extremes
+				rank := float64(sort.SearchFloat64s(values, p.GetValue())) / float64(len(values))
+				q := p.GetPercentile() / 100
+				if maxErr := 0.01; math.Abs(rank-q) > maxErr {
+					t.Errorf("p%v = %v, at rank %v", p.GetPercentile(), p.GetValue(), rank)
+				}
+			}
+			if first, last := res.GetPercentiles()[0], res.GetPercentiles()[len(percentiles)-1]; first.GetValue() != values[0] || last.GetValue() != values[len(values)-1] {
+				t.Errorf("p0, p100 = %v, %v, want the min and max %v, %v", first.GetValue(), last.GetValue(), values[0], values[len(values)-1])
+			}
+		})
+	}
+}
+
+func TestNewStatistics(t *testing.T) {
+	tests := []struct {
+		name        string
+		percentiles []float64
+		want        []float64
+		wantCode    codes.Code
+	}{
+		{name: "default", want: defaultPercentiles},
+		{name: "bounds", percentiles: []float64{0, 100}, want: []float64{0, 100}},
+		{name: "above 100", percentiles: []float64{101}, wantCode: codes.InvalidArgument},
+		{name: "negative", percentiles: []float64{-1}, wantCode: codes.InvalidArgument},
+		{name: "NaN", percentiles: []float64{math.NaN()}, wantCode: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newStatistics(&calculatorpb.ComputeStatisticsRequest{Percentiles: tt.percentiles})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if err == nil && len(s.percentiles) != len(tt.want) {
+				t.Errorf("percentiles = %v, want %v", s.percentiles, tt.want)
+			}
+		})
+	}
+}
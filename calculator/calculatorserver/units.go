@@ -0,0 +1,145 @@
+package calculatorserver
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+)
+
+// unit converts a value to the base unit of its dimension:
+// base = value*factor + offset, in exact arithmetic so that e.g. 0 C is
+// 32 F rather than 31.9999999999999.
+type unit struct {
+	dimension string
+	factor    *big.Rat
+	offset    *big.Rat
+}
+
+// units is the registry of the units, by symbol. The symbols are case
+// sensitive: "mB" is not "MB", and "b" is a bit while "B" is a byte.
+var units = map[string]unit{}
+
+// registerUnit adds a unit of the dimension under its symbols. The factor
+// and the offset are decimals, or fractions of decimals such as "5/9".
+func registerUnit(dimension string, factor string, offset string, symbols ...string) {
+	for _, symbol := range symbols {
+		if _, ok := units[symbol]; ok {
+			panic(fmt.Sprintf("calculatorserver: unit %q registered twice", symbol))
+		}
+		units[symbol] = unit{dimension: dimension, factor: mustParseRat(factor), offset: mustParseRat(offset)}
+	}
+}
+
+func mustParseRat(s string) *big.Rat {
+	num, denom, isFraction := strings.Cut(s, "/")
+	r, ok := new(big.Rat).SetString(num)
+	if isFraction {
+		d, dOK := new(big.Rat).SetString(denom)
+		ok = ok && dOK && d.Sign() != 0
+		if ok {
+			r.Quo(r, d)
+		}
+	}
+	if !ok {
+		panic(fmt.Sprintf("calculatorserver: invalid unit factor or offset %q", s))
+	}
+	return r
+}
+
+func init() {
+	// length, in meters
+	registerUnit("length", "1", "0", "m")
+	registerUnit("length", "1e3", "0", "km")
+	registerUnit("length", "1e-2", "0", "cm")
+	registerUnit("length", "1e-3", "0", "mm")
+	registerUnit("length", "1e-6", "0", "um", "µm")
+	registerUnit("length", "1e-9", "0", "nm")
+	registerUnit("length", "0.0254", "0", "in")
+	registerUnit("length", "0.3048", "0", "ft")
+	registerUnit("length", "0.9144", "0", "yd")
+	registerUnit("length", "1609.344", "0", "mi")
+	registerUnit("length", "1852", "0", "nmi")
+
+	// mass, in kilograms
+	registerUnit("mass", "1", "0", "kg")
+	registerUnit("mass", "1e-3", "0", "g")
+	registerUnit("mass", "1e-6", "0", "mg")
+	registerUnit("mass", "1e3", "0", "t")
+	registerUnit("mass", "0.45359237", "0", "lb")
+	registerUnit("mass", "0.45359237/16", "0", "oz")
+	registerUnit("mass", "6.35029318", "0", "st")
+
+	// temperature, in kelvins
+	registerUnit("temperature", "1", "0", "K")
+	registerUnit("temperature", "1", "273.15", "C", "degC", "°C")
+	registerUnit("temperature", "5/9", "2298.35/9", "F", "degF", "°F")
+
+	// time, in seconds
+	registerUnit("time", "1", "0", "s")
+	registerUnit("time", "1e-3", "0", "ms")
+	registerUnit("time", "1e-6", "0", "us", "µs")
+	registerUnit("time", "1e-9", "0", "ns")
+	registerUnit("time", "60", "0", "min")
+	registerUnit("time", "3600", "0", "h")
+	registerUnit("time", "86400", "0", "d")
+	registerUnit("time", "604800", "0", "wk")
+
+	// data size, in bytes
+	registerUnit("data", "1/8", "0", "b", "bit")
+	registerUnit("data", "1", "0", "B")
+	for i, prefix := range []string{"k", "M", "G", "T", "P"} {
+		registerUnit("data", fmt.Sprintf("1e%d", 3*(i+1)), "0", prefix+"B")
+		registerUnit("data", fmt.Sprintf("1e%d/8", 3*(i+1)), "0", prefix+"bit")
+	}
+	for i, prefix := range []string{"Ki", "Mi", "Gi", "Ti", "Pi"} {
+		registerUnit("data", strconv.FormatInt(int64(1)<<(10*(i+1)), 10), "0", prefix+"B")
+	}
+}
+
+// roundConversion rounds the result of a conversion to 15 significant
+// digits, the precision of a double, so that the errors of the doubles of
+// the values, e.g. 0.1, do not show in the results.
+func roundConversion(v float64) float64 {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 15, 64), 64)
+	return rounded
+}
+
+func (s *Server) convert(req *calculatorpb.ConvertRequest) (*calculatorpb.ConvertResponse, error) {
+	value := req.GetValue()
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil, grpcerr.InvalidArgument("value", fmt.Sprintf("Must be a finite number, received %v", value))
+	}
+
+	from, fromOK := units[req.GetFrom()]
+	to, toOK := units[req.GetTo()]
+	if !fromOK && !toOK && isCurrency(req.GetFrom()) && isCurrency(req.GetTo()) {
+		return s.rates.convert(value, req.GetFrom(), req.GetTo())
+	}
+
+	if !fromOK {
+		return nil, grpcerr.InvalidArgument("from", fmt.Sprintf("Unknown unit %q", req.GetFrom()))
+	}
+	if !toOK {
+		return nil, grpcerr.InvalidArgument("to", fmt.Sprintf("Unknown unit %q", req.GetTo()))
+	}
+	if from.dimension != to.dimension {
+		return nil, grpcerr.InvalidArgument("to", fmt.Sprintf("Cannot convert %s (%s) to %s (%s)", req.GetFrom(), from.dimension, req.GetTo(), to.dimension))
+	}
+
+	base := new(big.Rat).SetFloat64(value)
+	base.Mul(base, from.factor).Add(base, from.offset)
+	result, _ := base.Sub(base, to.offset).Quo(base, to.factor).Float64()
+	if math.IsInf(result, 0) {
+		return nil, grpcerr.OutOfRange("value", "The result overflows a double")
+	}
+
+	return &calculatorpb.ConvertResponse{
+		Value:     roundConversion(result),
+		Dimension: from.dimension,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package calculatorserver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConvertUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		from, to string
+		want     float64
+		wantDim  string
+		wantCode codes.Code
+	}{
+		{name: "length", value: 1, from: "mi", to: "km", want: 1.609344, wantDim: "length"},
+		{name: "mass", value: 1, from: "st", to: "lb", want: 14, wantDim: "mass"},
+		{name: "fahrenheit to kelvin", value: 32, from: "°F", to: "K", want: 273.15, wantDim: "temperature"},
+		{name: "kelvin to fahrenheit", value: 0, from: "K", to: "F", want: -459.67, wantDim: "temperature"},
+		{name: "celsius to fahrenheit", value: 100, from: "C", to: "F", want: 212, wantDim: "temperature"},
+		{name: "freezing point", value: 0, from: "C", to: "F", want: 32, wantDim: "temperature"},
+		{name: "fahrenheit to celsius", value: 32, from: "F", to: "C", want: 0, wantDim: "temperature"},
+		{name: "body temperature", value: 98.6, from: "F", to: "C", want: 37, wantDim: "temperature"},
+		{name: "same in celsius and fahrenheit", value: -40, from: "degC", to: "degF", want: -40, wantDim: "temperature"},
+		{name: "time", value: 2, from: "h", to: "min", want: 120, wantDim: "time"},
+		{name: "bits and bytes", value: 8, from: "Mbit", to: "MB", want: 1, wantDim: "data"},
+		{name: "binary prefix", value: 1, from: "GiB", to: "MB", want: 1073.741824, wantDim: "data"},
+
+		{name: "length to mass", value: 1, from: "m", to: "kg", wantCode: codes.InvalidArgument},
+		{name: "temperature to time", value: 1, from: "K", to: "s", wantCode: codes.InvalidArgument},
+		{name: "unit to currency", value: 1, from: "m", to: "EUR", wantCode: codes.InvalidArgument},
+		{name: "case sensitive", value: 1, from: "mB", to: "B", wantCode: codes.InvalidArgument},
+		{name: "unknown unit", value: 1, from: "m", to: "parsec", wantCode: codes.InvalidArgument},
+		{name: "NaN", value: math.NaN(), from: "m", to: "km", wantCode: codes.InvalidArgument},
+		{name: "overflow", value: math.MaxFloat64, from: "PB", to: "bit", wantCode: codes.OutOfRange},
+	}
+
+	s := &Server{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.convert(&calculatorpb.ConvertRequest{Value: tt.value, From: tt.from, To: tt.to})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if res.GetValue() != tt.want || res.GetDimension() != tt.wantDim {
+				t.Errorf("convert() = %v %s, want %v %s", res.GetValue(), res.GetDimension(), tt.want, tt.wantDim)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+// Command blogctl manages the blogs of the BlogService.
+//
+//	blogctl [flags] create -author pj -title "My blog" -content-file post.md
+//	blogctl [flags] get <id>
+//	blogctl [flags] update [-author a] [-title t] [-content c | -content-file f] <id>
+//	blogctl [flags] delete <id>
+//	blogctl [flags] list
+//	blogctl [flags] download [-out file] <file name>
+//	blogctl [flags] import [-format jsonl|csv] [-dry-run] [file]
+//	blogctl [flags] export [-format jsonl|csv] [-author a] [-out file]
+//
+// The blogs are printed as a table, JSON or YAML (-o). A -content-file of
+// "-" reads the content from the standard input. The exit code is 0 on
+// success, 2 on a usage error, 1 on a local error and 10 + the gRPC status
+// code on the error of a call, e.g. 15 for NotFound.
+//
+// import and export read and write JSON lines, or CSV with a header row
+// naming the columns id, author_id, title and content. The blogs are
+// imported with their id when they have one. The blogs not imported are
+// reported with their line on the standard error, and the exit code is
+// then 1.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogclient"
+	"github.com/pjserol/tuto-grpc-go/cmd/internal/cli"
+)
+
+// commandFunc runs a command with the flags and arguments following its
+// name.
+type commandFunc func(ctx context.Context, c *blogclient.Client, p printer, args []string) error
+
+var commands = map[string]commandFunc{
+	"create":   runCreate,
+	"get":      runGet,
+	"update":   runUpdate,
+	"delete":   runDelete,
+	"list":     runList,
+	"download": runDownload,
+	"import":   runImport,
+	"export":   runExport,
+}
+
+// bulkCommands are the commands without default deadline.
+var bulkCommands = map[string]bool{"import": true, "export": true}
+
+// usages are the usages of the commands, in the order they are printed.
+var usages = cli.Usages{
+	{"create", "create -author <author> -title <title> [-content <text> | -content-file <file>]"},
+	{"get", "get <id>"},
+	{"update", "update [-author <author>] [-title <title>] [-content <text> | -content-file <file>] <id>"},
+	{"delete", "delete <id>"},
+	{"list", "list"},
+	{"download", "download [-out <file>] <file name>"},
+	{"import", "import [-format jsonl|csv] [-dry-run] [file]"},
+	{"export", "export [-format jsonl|csv] [-author <author>] [-out <file>]"},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	env := blogclient.OptionsFromEnv()
+
+	flags := flag.NewFlagSet("blogctl", flag.ContinueOnError)
+	conn := cli.AddConnFlags(flags, "BLOG_ADDR")
+	token := flags.String("token", env.Token, "bearer token sent to the server, BLOG_TOKEN by default")
+	timeout := flags.Duration("timeout", 10*time.Second, "deadline of the command, none for import and export unless set")
+	format := flags.String("o", "table", "output format: table, json or yaml")
+	flags.Usage = usages.Usage(flags, "blogctl [flags] <command> [command flags] [args]")
+	if err := flags.Parse(args); err != nil {
+		return cli.ExitUsage
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		if flags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "blogctl: unknown command %q\n", flags.Arg(0))
+		}
+		flags.Usage()
+		return cli.ExitUsage
+	}
+
+	p, err := newPrinter(*format, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "blogctl: %v\n", err)
+		return cli.ExitUsage
+	}
+
+	opts := blogclient.Options{CAFile: conn.TLSCAFile(), ServerName: conn.ServerName, Token: *token, Timeout: *timeout}
+	c, err := blogclient.Dial(conn.Addr, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "blogctl: cannot connect to %s: %v\n", conn.Addr, err)
+		return cli.ExitError
+	}
+	defer c.Close()
+
+	// the bulk commands have no deadline, unless -timeout is set
+	ctx := context.Background()
+	timeoutSet := false
+	flags.Visit(func(f *flag.Flag) { timeoutSet = timeoutSet || f.Name == "timeout" })
+	if !bulkCommands[flags.Arg(0)] || timeoutSet {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	return cli.ExitCode("blogctl", cmd(ctx, c, p, flags.Args()[1:]))
+}
+
+// commandFlags returns the flag set of the command name.
+func commandFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: blogctl [flags] %s\n", usages.Of(name))
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse parses the flags of a command, followed by n arguments.
+func parse(flags *flag.FlagSet, args []string, n int) error {
+	if err := flags.Parse(args); err != nil {
+		return cli.ErrUsage
+	}
+	if flags.NArg() != n {
+		flags.Usage()
+		return cli.ErrUsage
+	}
+	return nil
+}
+
+// contentFlags are the flags setting the content of a blog.
+type contentFlags struct {
+	text string
+	file string
+}
+
+func newContentFlags(flags *flag.FlagSet) *contentFlags {
+	f := &contentFlags{}
+	flags.StringVar(&f.text, "content", "", "content of the blog")
+	flags.StringVar(&f.file, "content-file", "", "file of the content of the blog, - for the standard input")
+	return f
+}
+
+// read returns the content, and whether it was set.
+func (f *contentFlags) read() (string, bool, error) {
+	switch {
+	case f.text != "" && f.file != "":
+		return "", false, errors.New("-content and -content-file cannot be both set")
+	case f.file == "-":
+		b, err := io.ReadAll(os.Stdin)
+		return string(b), true, err
+	case f.file != "":
+		b, err := os.ReadFile(f.file)
+		return string(b), true, err
+	}
+	return f.text, f.text != "", nil
+}
+
+func runCreate(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("create")
+	author := flags.String("author", "", "id of the author")
+	title := flags.String("title", "", "title of the blog")
+	content := newContentFlags(flags)
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	text, _, err := content.read()
+	if err != nil {
+		return err
+	}
+	blog, err := c.Create(ctx, blogclient.Blog{AuthorID: *author, Title: *title, Content: text})
+	if err != nil {
+		return err
+	}
+	return p.blog(blog)
+}
+
+func runGet(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("get")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	blog, err := c.Read(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return p.blog(blog)
+}
+
+// runUpdate updates the fields set by the flags, and keeps the others.
+func runUpdate(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("update")
+	author := flags.String("author", "", "id of the author")
+	title := flags.String("title", "", "title of the blog")
+	content := newContentFlags(flags)
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	text, contentSet, err := content.read()
+	if err != nil {
+		return err
+	}
+	blog, err := c.Read(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "author":
+			blog.AuthorID = *author
+		case "title":
+			blog.Title = *title
+		}
+	})
+	if contentSet {
+		blog.Content = text
+	}
+
+	blog, err = c.Update(ctx, blog)
+	if err != nil {
+		return err
+	}
+	return p.blog(blog)
+}
+
+func runDelete(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("delete")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	return c.Delete(ctx, flags.Arg(0))
+}
+
+func runList(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("list")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	blogs := c.List(ctx)
+	for blogs.Next() {
+		if err := p.list(blogs.Blog()); err != nil {
+			return err
+		}
+	}
+	if err := blogs.Err(); err != nil {
+		return err
+	}
+	return p.flush()
+}
+
+// runDownload writes the image to -out, its base name by default, or to
+// the standard output when -out is "-".
+func runDownload(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("download")
+	out := flags.String("out", "", "file the image is written to, - for the standard output")
+	if err := parse(flags, args, 1); err != nil {
+		return err
+	}
+
+	name := flags.Arg(0)
+	if *out == "" {
+		*out = filepath.Base(name)
+	}
+	if *out == "-" {
+		_, err := c.DownloadImage(ctx, name, os.Stdout)
+		return err
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	n, err := c.DownloadImage(ctx, name, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// the image is incomplete
+		os.Remove(*out)
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%d bytes written to %s\n", n, *out)
+	return nil
+}
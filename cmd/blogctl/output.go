@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogclient"
+)
+
+// maxTableContent is the number of characters of the content shown in a
+// table.
+const maxTableContent = 40
+
+// blogOutput is a blog as printed in JSON or YAML.
+type blogOutput struct {
+	ID       string `json:"id"`
+	AuthorID string `json:"author_id"`
+	Title    string `json:"title"`
+	Content  string `json:"content"`
+}
+
+// fields returns the fields of b in the order they are printed.
+func (b blogOutput) fields() [][2]string {
+	return [][2]string{{"id", b.ID}, {"author_id", b.AuthorID}, {"title", b.Title}, {"content", b.Content}}
+}
+
+// importOutput is the result of an import, as printed in JSON or YAML.
+type importOutput struct {
+	Created int  `json:"created"`
+	Updated int  `json:"updated"`
+	Failed  int  `json:"failed"`
+	DryRun  bool `json:"dry_run"`
+}
+
+// printer prints the blogs in a format.
+type printer interface {
+	// blog prints a blog read, created or updated
+	blog(b blogclient.Blog) error
+	// list prints a blog of a list
+	list(b blogclient.Blog) error
+	// flush prints the end of a list
+	flush() error
+	// importResult prints the result of an import
+	importResult(r importOutput) error
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table":
+		return &tablePrinter{w: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)}, nil
+	case "json":
+		return &jsonPrinter{w: w}, nil
+	case "yaml":
+		return &yamlPrinter{w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, expected table, json or yaml", format)
+}
+
+// tablePrinter prints the blogs as a table, with the first line of their
+// content truncated.
+type tablePrinter struct {
+	w      *tabwriter.Writer
+	header bool
+}
+
+func (p *tablePrinter) blog(b blogclient.Blog) error {
+	if err := p.list(b); err != nil {
+		return err
+	}
+	return p.flush()
+}
+
+func (p *tablePrinter) list(b blogclient.Blog) error {
+	if !p.header {
+		p.header = true
+		fmt.Fprintln(p.w, "ID\tAUTHOR\tTITLE\tCONTENT")
+	}
+	_, err := fmt.Fprintf(p.w, "%s\t%s\t%s\t%s\n", b.ID, cell(b.AuthorID, 0), cell(b.Title, 0), cell(b.Content, maxTableContent))
+	return err
+}
+
+func (p *tablePrinter) flush() error {
+	return p.w.Flush()
+}
+
+func (p *tablePrinter) importResult(r importOutput) error {
+	fmt.Fprintln(p.w, "CREATED\tUPDATED\tFAILED\tDRY RUN")
+	fmt.Fprintf(p.w, "%d\t%d\t%d\t%t\n", r.Created, r.Updated, r.Failed, r.DryRun)
+	return p.w.Flush()
+}
+
+// cell returns the first line of s without tabs, truncated to max
+// characters when max is not zero.
+func cell(s string, max int) string {
+	line, _, more := strings.Cut(s, "\n")
+	line = strings.ReplaceAll(line, "\t", " ")
+	if r := []rune(line); max > 0 && len(r) > max {
+		line, more = string(r[:max-1]), true
+	}
+	if more {
+		line += "…"
+	}
+	return line
+}
+
+// jsonPrinter prints a blog as a JSON object, and a list as a JSON array.
+type jsonPrinter struct {
+	w     io.Writer
+	blogs []blogOutput
+}
+
+func (p *jsonPrinter) blog(b blogclient.Blog) error {
+	return p.encode(blogOutput(b))
+}
+
+func (p *jsonPrinter) list(b blogclient.Blog) error {
+	p.blogs = append(p.blogs, blogOutput(b))
+	return nil
+}
+
+func (p *jsonPrinter) flush() error {
+	if p.blogs == nil {
+		p.blogs = []blogOutput{}
+	}
+	return p.encode(p.blogs)
+}
+
+func (p *jsonPrinter) importResult(r importOutput) error {
+	return p.encode(r)
+}
+
+func (p *jsonPrinter) encode(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// yamlPrinter prints a blog as a YAML mapping, and a list as a YAML
+// sequence of mappings, streamed. The values are double-quoted scalars,
+// whose escapes are those of JSON.
+type yamlPrinter struct {
+	w     io.Writer
+	count int
+}
+
+func (p *yamlPrinter) blog(b blogclient.Blog) error {
+	return p.mapping(blogOutput(b), "", "")
+}
+
+func (p *yamlPrinter) list(b blogclient.Blog) error {
+	p.count++
+	return p.mapping(blogOutput(b), "- ", "  ")
+}
+
+func (p *yamlPrinter) flush() error {
+	if p.count == 0 {
+		_, err := fmt.Fprintln(p.w, "[]")
+		return err
+	}
+	return nil
+}
+
+func (p *yamlPrinter) importResult(r importOutput) error {
+	_, err := fmt.Fprintf(p.w, "created: %d\nupdated: %d\nfailed: %d\ndry_run: %t\n", r.Created, r.Updated, r.Failed, r.DryRun)
+	return err
+}
+
+// mapping prints b, its first line prefixed by first and the others by
+// indent.
+func (p *yamlPrinter) mapping(b blogOutput, first string, indent string) error {
+	for i, f := range b.fields() {
+		prefix := indent
+		if i == 0 {
+			prefix = first
+		}
+		var value bytes.Buffer
+		enc := json.NewEncoder(&value)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(f[1]); err != nil {
+			return err
+		}
+		// Encode ends the value with a newline
+		if _, err := fmt.Fprintf(p.w, "%s%s: %s", prefix, f[0], value.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogclient"
+	"github.com/pjserol/tuto-grpc-go/cmd/internal/cli"
+)
+
+// maxLineSize is the size of the longest JSON line read, a blog having up
+// to 100000 characters of content.
+const maxLineSize = 1 << 20
+
+// csvColumns are the columns of the CSV files, in the order they are
+// written.
+var csvColumns = []string{"id", "author_id", "title", "content"}
+
+// fileFormat returns the format flag, or the format of the extension of
+// path: csv for .csv, jsonl for the others.
+func fileFormat(format string, path string) (string, error) {
+	switch format {
+	case "jsonl", "csv":
+		return format, nil
+	case "":
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			return "csv", nil
+		}
+		return "jsonl", nil
+	}
+	return "", fmt.Errorf("unknown file format %q, expected jsonl or csv", format)
+}
+
+// record is a blog read from a file, or the error of its line.
+type record struct {
+	line int
+	blog blogclient.Blog
+	err  error
+}
+
+// readJSONL sends the blogs of the JSON lines of r, skipping the empty
+// lines, and returns the error that stopped the reading.
+func readJSONL(ctx context.Context, r io.Reader, records chan<- record) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var b blogOutput
+		dec := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&b)
+		if !send(ctx, records, record{line: line, blog: blogclient.Blog(b), err: err}) {
+			return ctx.Err()
+		}
+	}
+	return scanner.Err()
+}
+
+// readCSV sends the blogs of the rows of r, after its header row, and
+// returns the error that stopped the reading.
+func readCSV(ctx context.Context, r io.Reader, records chan<- record) error {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("cannot read the CSV header: %v", err)
+	}
+	// the index of the columns, by name
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !contains(csvColumns, name) {
+			return fmt.Errorf("unknown CSV column %q, expected %s", name, strings.Join(csvColumns, ", "))
+		}
+		columns[name] = i
+	}
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var line int
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// the reader continues after the invalid row, whose fields
+			// have no position
+			line = parseErr.StartLine
+		} else if err != nil {
+			return err
+		} else {
+			line, _ = cr.FieldPos(0)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		rec := record{line: line, err: err, blog: blogclient.Blog{
+			ID:       field("id"),
+			AuthorID: field("author_id"),
+			Title:    field("title"),
+			Content:  field("content"),
+		}}
+		if !send(ctx, records, rec) {
+			return ctx.Err()
+		}
+	}
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func send(ctx context.Context, records chan<- record, rec record) bool {
+	select {
+	case records <- rec:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// importError is a blog not imported, reported with its line.
+type importError struct {
+	line int
+	err  error
+}
+
+// runImport sends the blogs read from the file, or from the standard input,
+// and reports the lines that cannot be parsed along with the blogs not
+// imported.
+func runImport(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("import")
+	format := flags.String("format", "", "file format: jsonl or csv, from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "check and count the blogs without writing them")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		if err == nil {
+			flags.Usage()
+		}
+		return cli.ErrUsage
+	}
+
+	path := flags.Arg(0)
+	f, err := fileFormat(*format, path)
+	if err != nil {
+		return err
+	}
+	r := io.Reader(os.Stdin)
+	if path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := make(chan record)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(records)
+		if f == "csv" {
+			readErr <- readCSV(ctx, r, records)
+		} else {
+			readErr <- readJSONL(ctx, r, records)
+		}
+	}()
+
+	// the blogs sent are those of the records without error, whose lines
+	// are kept to report the errors of the server
+	out := importOutput{DryRun: *dryRun}
+	var errs []importError
+	var lines []int
+	blogs := make(chan blogclient.Blog)
+	go func() {
+		defer close(blogs)
+		for rec := range records {
+			if rec.err != nil {
+				out.Failed++
+				errs = append(errs, importError{line: rec.line, err: rec.err})
+				continue
+			}
+			lines = append(lines, rec.line)
+			select {
+			case blogs <- rec.blog:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	res, err := c.Import(ctx, blogs, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	// the blogs read before an error of the reader are imported, so the
+	// result is printed before the error
+	out.Created, out.Updated = res.Created, res.Updated
+	out.Failed += res.Failed
+	for _, e := range res.Errors {
+		// the index comes from the server
+		line := 0
+		if e.Index >= 0 && e.Index < len(lines) {
+			line = lines[e.Index]
+		}
+		errs = append(errs, importError{line: line, err: e.Err})
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].line < errs[j].line })
+
+	for _, e := range errs {
+		if e.line > 0 {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", e.line, e.err)
+		} else {
+			fmt.Fprintf(os.Stderr, "unknown line: %v\n", e.err)
+		}
+	}
+	if more := out.Failed - len(errs); more > 0 {
+		fmt.Fprintf(os.Stderr, "and %d other blogs not imported\n", more)
+	}
+	if err := p.importResult(out); err != nil {
+		return err
+	}
+	if err := <-readErr; err != nil {
+		return fmt.Errorf("cannot read the blogs after those imported: %v", err)
+	}
+	if out.Failed > 0 {
+		return fmt.Errorf("%d blogs not imported", out.Failed)
+	}
+	return nil
+}
+
+// runExport writes the blogs to -out, or to the standard output.
+func runExport(ctx context.Context, c *blogclient.Client, p printer, args []string) error {
+	flags := commandFlags("export")
+	format := flags.String("format", "", "file format: jsonl or csv, from the extension of -out by default")
+	author := flags.String("author", "", "export the blogs of the author only")
+	out := flags.String("out", "-", "file the blogs are written to, - for the standard output")
+	if err := parse(flags, args, 0); err != nil {
+		return err
+	}
+
+	f, err := fileFormat(*format, *out)
+	if err != nil {
+		return err
+	}
+	w := io.Writer(os.Stdout)
+	var file *os.File
+	if *out != "-" {
+		if file, err = os.Create(*out); err != nil {
+			return err
+		}
+		w = file
+	}
+
+	n, err := export(c.Export(ctx, *author), f, w)
+	if file != nil {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			// the export is incomplete
+			os.Remove(*out)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%d blogs exported\n", n)
+	return nil
+}
+
+// export writes the blogs to w in the format, and returns their number.
+func export(blogs *blogclient.Blogs, format string, w io.Writer) (int, error) {
+	bw := bufio.NewWriter(w)
+	write := func(b blogclient.Blog) error {
+		enc := json.NewEncoder(bw)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(blogOutput(b))
+	}
+	flush := bw.Flush
+	if format == "csv" {
+		cw := csv.NewWriter(bw)
+		if err := cw.Write(csvColumns); err != nil {
+			return 0, err
+		}
+		write = func(b blogclient.Blog) error {
+			return cw.Write([]string{b.ID, b.AuthorID, b.Title, b.Content})
+		}
+		flush = func() error {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return bw.Flush()
+		}
+	}
+
+	n := 0
+	for blogs.Next() {
+		if err := write(blogs.Blog()); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := blogs.Err(); err != nil {
+		return n, err
+	}
+	return n, flush()
+}
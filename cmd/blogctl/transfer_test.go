@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogclient"
+)
+
+// read returns the records read by readFunc from input, and its error.
+func read(readFunc func(context.Context, io.Reader, chan<- record) error, input string) ([]record, error) {
+	records := make(chan record)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(records)
+		errc <- readFunc(context.Background(), strings.NewReader(input), records)
+	}()
+
+	var got []record
+	for rec := range records {
+		got = append(got, rec)
+	}
+	return got, <-errc
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		lines   []int
+		failed  []bool
+		blogs   []blogclient.Blog
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			input: "id,author_id,title,content\n1,a,t,\"multi\nline\"\n,b,u,c\n",
+			lines: []int{2, 4},
+			blogs: []blogclient.Blog{
+				{ID: "1", AuthorID: "a", Title: "t", Content: "multi\nline"},
+				{AuthorID: "b", Title: "u", Content: "c"},
+			},
+		},
+		{
+			name:  "columns by name",
+			input: "title,author_id\nt,a\n",
+			lines: []int{2},
+			blogs: []blogclient.Blog{{AuthorID: "a", Title: "t"}},
+		},
+		{
+			name:   "bare quote in the first field",
+			input:  "author_id,title\na\"b,t\nc,u\n",
+			lines:  []int{2, 3},
+			failed: []bool{true, false},
+		},
+		{
+			name:   "unterminated quote",
+			input:  "author_id,title\nq,\"unterminated\n",
+			lines:  []int{2},
+			failed: []bool{true},
+		},
+		{
+			name:    "unknown column",
+			input:   "title,bad\nx,y\n",
+			wantErr: true,
+		},
+		{
+			name:    "no header",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := read(readCSV, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			checkRecords(t, got, tt.lines, tt.failed, tt.blogs)
+		})
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		lines   []int
+		failed  []bool
+		blogs   []blogclient.Blog
+		wantErr error
+	}{
+		{
+			name:  "valid",
+			input: "{\"id\":\"1\",\"author_id\":\"a\",\"title\":\"t\"}\n\n{\"author_id\":\"b\",\"title\":\"u\",\"content\":\"c\"}\n",
+			lines: []int{1, 3},
+			blogs: []blogclient.Blog{
+				{ID: "1", AuthorID: "a", Title: "t"},
+				{AuthorID: "b", Title: "u", Content: "c"},
+			},
+		},
+		{
+			name:   "invalid lines",
+			input:  "not json\n{\"title\":\"t\",\"extra\":1}\n",
+			lines:  []int{1, 2},
+			failed: []bool{true, true},
+		},
+		{
+			name:    "line too long",
+			input:   "{\"title\":\"t\"}\n" + strings.Repeat("x", maxLineSize+1) + "\n",
+			lines:   []int{1},
+			blogs:   []blogclient.Blog{{Title: "t"}},
+			wantErr: bufio.ErrTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := read(readJSONL, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("readJSONL() error = %v, want %v", err, tt.wantErr)
+			}
+			checkRecords(t, got, tt.lines, tt.failed, tt.blogs)
+		})
+	}
+}
+
+// checkRecords checks the lines of got, which records failed, and the
+// blogs of those that did not when blogs is not nil.
+func checkRecords(t *testing.T, got []record, lines []int, failed []bool, blogs []blogclient.Blog) {
+	t.Helper()
+
+	if len(got) != len(lines) {
+		t.Fatalf("got %d records, want %d: %+v", len(got), len(lines), got)
+	}
+	var gotBlogs []blogclient.Blog
+	for i, rec := range got {
+		if rec.line != lines[i] {
+			t.Errorf("record %d: line = %d, want %d", i, rec.line, lines[i])
+		}
+		wantFailed := failed != nil && failed[i]
+		if (rec.err != nil) != wantFailed {
+			t.Errorf("record %d: error = %v, want failed %v", i, rec.err, wantFailed)
+		}
+		if rec.err == nil {
+			gotBlogs = append(gotBlogs, rec.blog)
+		}
+	}
+	if blogs == nil {
+		return
+	}
+	if len(gotBlogs) != len(blogs) {
+		t.Fatalf("got blogs %+v, want %+v", gotBlogs, blogs)
+	}
+	for i := range blogs {
+		if gotBlogs[i] != blogs[i] {
+			t.Errorf("blog %d = %+v, want %+v", i, gotBlogs[i], blogs[i])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Package cli holds what the commands of cmd share: the exit codes, the
+// usages of their subcommands, the flags of the connection to the server
+// and the defaults read from the environment.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"github.com/pjserol/tuto-grpc-go/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Exit codes of the commands.
+const (
+	ExitError = 1
+	ExitUsage = 2
+	// ExitStatus is added to the gRPC status code of the errors of the
+	// calls
+	ExitStatus = 10
+)
+
+// DefaultCAFile is the certificate of the CA of the servers, see
+// ssl/instructions.sh.
+const DefaultCAFile = "ssl/ca.crt"
+
+// ErrUsage is returned after printing the usage of a subcommand.
+var ErrUsage = errors.New("usage error")
+
+// Usages are the usages of the subcommands of a command, by name, in the
+// order they are printed.
+type Usages [][2]string
+
+// Of returns the usage of the subcommand name.
+func (u Usages) Of(name string) string {
+	for _, usage := range u {
+		if usage[0] == name {
+			return usage[1]
+		}
+	}
+	return name
+}
+
+// Usage returns the usage function of the flags of a command, printing
+// synopsis, the subcommands and the flags.
+func (u Usages) Usage(flags *flag.FlagSet, synopsis string) func() {
+	return func() {
+		out := flags.Output()
+		fmt.Fprintf(out, "Usage: %s\n", synopsis)
+		fmt.Fprintln(out, "\nCommands:")
+		for _, usage := range u {
+			fmt.Fprintf(out, "  %s\n", usage[1])
+		}
+		fmt.Fprintln(out, "\nFlags:")
+		flags.PrintDefaults()
+	}
+}
+
+// ExitCode prints err, prefixed by the name of the command, and returns
+// its exit code: 0 when err is nil, ExitUsage for ErrUsage, whose usage is
+// already printed, ExitStatus + the gRPC status code for the error of a
+// call, printed with its field violations and retry delay, and ExitError
+// for the others.
+func ExitCode(name string, err error) int {
+	if err == nil {
+		return 0
+	}
+	if err == ErrUsage {
+		return ExitUsage
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+	st, ok := status.FromError(err)
+	if !ok {
+		return ExitError
+	}
+	if req, ok := grpcerr.BadRequest(err); ok {
+		for _, v := range req.GetFieldViolations() {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", v.GetField(), v.GetDescription())
+		}
+	}
+	if delay, ok := grpcerr.RetryDelay(err); ok {
+		fmt.Fprintf(os.Stderr, "  retry after %v\n", delay)
+	}
+	return ExitStatus + int(st.Code())
+}
+
+// ConnFlags are the flags of the connection to the server.
+type ConnFlags struct {
+	Addr       string
+	Insecure   bool
+	CAFile     string
+	ServerName string
+}
+
+// AddConnFlags adds the flags of the connection to flags: the address,
+// from the environment variable addrEnv by default, and TLS, disabled by
+// default in the environment where the servers do not use it.
+func AddConnFlags(flags *flag.FlagSet, addrEnv string) *ConnFlags {
+	c := &ConnFlags{}
+	flags.StringVar(&c.Addr, "addr", EnvOr(addrEnv, "localhost:50051"), "address of the server, "+addrEnv+" by default")
+	flags.BoolVar(&c.Insecure, "insecure", !server.TLSFromEnv(), "connect without TLS, the default when Environment=local")
+	flags.StringVar(&c.CAFile, "ca", DefaultCAFile, "certificate of the CA of the server")
+	flags.StringVar(&c.ServerName, "server-name", "", "name of the server checked in its certificate")
+	return c
+}
+
+// TLSCAFile returns the certificate of the CA, or an empty string without
+// TLS.
+func (c *ConnFlags) TLSCAFile() string {
+	if c.Insecure {
+		return ""
+	}
+	return c.CAFile
+}
+
+// DialOptions returns the options of grpc.Dial.
+func (c *ConnFlags) DialOptions() ([]grpc.DialOption, error) {
+	if c.Insecure {
+		return []grpc.DialOption{grpc.WithInsecure()}, nil
+	}
+	creds, err := credentials.NewClientTLSFromFile(c.CAFile, c.ServerName)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.DialOption{grpc.WithTransportCredentials(creds)}, nil
+}
+
+// EnvOr returns the value of the environment variable key, or def when it
+// is not set.
+func EnvOr(key string, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,70 @@
+// Command server hosts the greet, calculator and blog services on one
+// gRPC server.
+//
+//	go run cmd/server/main.go -services greet,calculator -addr 0.0.0.0:50051
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pjserol/tuto-grpc-go/blog/blogserver"
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorserver"
+	"github.com/pjserol/tuto-grpc-go/cmd/internal/cli"
+	"github.com/pjserol/tuto-grpc-go/greet/greetserver"
+	"github.com/pjserol/tuto-grpc-go/server"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func main() {
+	addr := flag.String("addr", server.DefaultAddr, "address to listen on")
+	services := flag.String("services", cli.EnvOr("SERVICES", "greet,calculator,blog"), "comma separated list of the services to host: greet, calculator, blog")
+	mongoURI := flag.String("mongo", cli.EnvOr("MONGO_URI", blogserver.DefaultMongoURI), "MongoDB URI of the blog service")
+	flag.Parse()
+
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	var mongoClient *mongo.Client
+	var hosted []server.Service
+	for _, name := range strings.Split(*services, ",") {
+		switch strings.TrimSpace(name) {
+		case "greet":
+			hosted = append(hosted, greetserver.Service())
+		case "calculator":
+			service, err := calculatorserver.Service()
+			if err != nil {
+				log.Fatal(err)
+			}
+			hosted = append(hosted, service)
+		case "blog":
+			log.Println("Connection to mongoDB")
+			client, err := blogserver.Connect(context.TODO(), *mongoURI)
+			if err != nil {
+				log.Fatal(err)
+			}
+			mongoClient = client
+			hosted = append(hosted, blogserver.Service(client))
+		case "":
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown service %q\n", name)
+			flag.Usage()
+			os.Exit(2)
+		}
+	}
+	if len(hosted) == 0 {
+		log.Fatal("No service to host")
+	}
+
+	if err := server.Run(server.Options{Addr: *addr, TLS: server.TLSFromEnv()}, hosted...); err != nil {
+		log.Fatalf("failed to serve: %v ", err)
+	}
+
+	if mongoClient != nil {
+		log.Println("Closing mongoDB connection")
+		mongoClient.Disconnect(context.TODO())
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pjserol/tuto-grpc-go/cmd/internal/cli"
+)
+
+// parseInt32 parses the argument name of a command.
+func parseInt32(name string, s string) (int32, error) {
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: must be a 32-bit integer", name, s)
+	}
+	return int32(n), nil
+}
+
+// numbers sends the numbers of args, or of the lines of the standard
+// input, separated by spaces, on the returned channel. wait stops reading
+// the input, and returns its error.
+func numbers(ctx context.Context, e *env, args []string) (ch <-chan int32, wait func() error) {
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan int32)
+	done := make(chan struct{})
+	var err error
+
+	go func() {
+		defer close(done)
+		defer close(out)
+		defer cancel()
+
+		names, errc := lines(ctx, e.stdin, args)
+		for line := range names {
+			for _, field := range strings.Fields(line) {
+				var n int32
+				if n, err = parseInt32("number", field); err != nil {
+					return
+				}
+				select {
+				case out <- n:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		// once canceled, the error of the call is that of the context
+		if lerr := <-errc; ctx.Err() == nil {
+			err = lerr
+		}
+	}()
+
+	return out, func() error {
+		cancel()
+		<-done
+		return err
+	}
+}
+
+func runSum(ctx context.Context, e *env, args []string) error {
+	if len(args) != 2 {
+		return cli.ErrUsage
+	}
+	a, err := parseInt32("a", args[0])
+	if err != nil {
+		return err
+	}
+	b, err := parseInt32("b", args[1])
+	if err != nil {
+		return err
+	}
+
+	sum, err := e.calc.Sum(ctx, a, b)
+	if err != nil {
+		return err
+	}
+	return e.out.print(strconv.Itoa(int(sum)), struct {
+		Sum int32 `json:"sum"`
+	}{sum})
+}
+
+func runSqrt(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return cli.ErrUsage
+	}
+	n, err := parseInt32("n", args[0])
+	if err != nil {
+		return err
+	}
+
+	root, err := e.calc.SquareRoot(ctx, n)
+	if err != nil {
+		return err
+	}
+	return e.out.print(strconv.FormatFloat(root, 'g', -1, 64), struct {
+		Root float64 `json:"root"`
+	}{root})
+}
+
+// runFactor prints the prime factors, as p^k in text.
+func runFactor(ctx context.Context, e *env, args []string) error {
+	if len(args) != 1 {
+		return cli.ErrUsage
+	}
+	n, ok := new(big.Int).SetString(args[0], 10)
+	if !ok {
+		return fmt.Errorf("invalid n %q: must be an integer", args[0])
+	}
+
+	factors := e.calc.Factorize(ctx, n)
+	for factors.Next() {
+		f := factors.Factor()
+		err := e.out.print(fmt.Sprintf("%s^%d", f.Prime, f.Multiplicity), struct {
+			Prime        string `json:"prime"`
+			Multiplicity int    `json:"multiplicity"`
+		}{f.Prime.String(), f.Multiplicity})
+		if err != nil {
+			return err
+		}
+	}
+	return factors.Err()
+}
+
+// runAverage calls Average with the numbers of args, retried when the
+// server is unavailable, or AverageStream with those of the standard
+// input.
+func runAverage(ctx context.Context, e *env, args []string) error {
+	var average float64
+	if len(args) > 0 {
+		var nums []int32
+		for _, arg := range args {
+			n, err := parseInt32("number", arg)
+			if err != nil {
+				return err
+			}
+			nums = append(nums, n)
+		}
+
+		var err error
+		if average, err = e.calc.Average(ctx, nums); err != nil {
+			return err
+		}
+	} else {
+		nums, wait := numbers(ctx, e, nil)
+		var err error
+		average, err = e.calc.AverageStream(ctx, nums)
+		// the input error, that ended the stream, comes first
+		if werr := wait(); werr != nil {
+			return werr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.out.print(strconv.FormatFloat(average, 'g', -1, 64), struct {
+		Average float64 `json:"average"`
+	}{average})
+}
+
+// runMax sends the numbers as they are read, and prints the maximums as
+// they are received.
+func runMax(ctx context.Context, e *env, args []string) error {
+	nums, wait := numbers(ctx, e, args)
+	maximums := e.calc.Max(ctx, nums)
+	for maximums.Next() {
+		max := maximums.Maximum()
+		err := e.out.print(strconv.Itoa(int(max)), struct {
+			Maximum int32 `json:"maximum"`
+		}{max})
+		if err != nil {
+			wait()
+			return err
+		}
+	}
+	if werr := wait(); werr != nil {
+		return werr
+	}
+	return maximums.Err()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pjserol/tuto-grpc-go/cmd/internal/cli"
+	"github.com/pjserol/tuto-grpc-go/greet/greetpb"
+)
+
+// result is the JSON output of the greet commands.
+type result struct {
+	Result string `json:"result"`
+}
+
+// greeting returns the greeting of a name, its first word being the first
+// name.
+func greeting(name string) *greetpb.Greeting {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return &greetpb.Greeting{}
+	}
+	return &greetpb.Greeting{
+		FistName: fields[0],
+		LastName: strings.Join(fields[1:], " "),
+	}
+}
+
+// nameArg returns the name of the arguments of a unary greet command.
+func nameArg(args []string) (*greetpb.Greeting, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, cli.ErrUsage
+	}
+	return greeting(strings.Join(args, " ")), nil
+}
+
+func runGreet(ctx context.Context, e *env, args []string) error {
+	g, err := nameArg(args)
+	if err != nil {
+		return err
+	}
+
+	res, err := e.greet.Greet(ctx, &greetpb.GreetRequest{Greeting: g})
+	if err != nil {
+		return err
+	}
+	return e.out.print(res.GetResult(), result{res.GetResult()})
+}
+
+func runGreetWithDeadline(ctx context.Context, e *env, args []string) error {
+	g, err := nameArg(args)
+	if err != nil {
+		return err
+	}
+
+	res, err := e.greet.GreetWithDeadline(ctx, &greetpb.GreetWithDeadlineRequest{Greeting: g})
+	if err != nil {
+		return err
+	}
+	return e.out.print(res.GetResult(), result{res.GetResult()})
+}
+
+func runGreetMany(ctx context.Context, e *env, args []string) error {
+	g, err := nameArg(args)
+	if err != nil {
+		return err
+	}
+
+	stream, err := e.greet.GreetManyTimes(ctx, &greetpb.GreetManyTimesRequest{Greeting: g})
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := e.out.print(res.GetResult(), result{res.GetResult()}); err != nil {
+			return err
+		}
+	}
+}
+
+func runLongGreet(ctx context.Context, e *env, args []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := e.greet.LongGreet(ctx)
+	if err != nil {
+		return err
+	}
+
+	names, errc := lines(ctx, e.stdin, args)
+	for name := range names {
+		if err := stream.Send(&greetpb.LongGreetRequest{Greeting: greeting(name)}); err == io.EOF {
+			// the server ended the call, CloseAndRecv returns its error
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := <-errc; err != nil {
+		return err
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return e.out.print(res.GetResult(), result{res.GetResult()})
+}
+
+// runGreetEveryone sends the names as they are read, and prints the
+// greetings as they are received.
+func runGreetEveryone(ctx context.Context, e *env, args []string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := e.greet.GreetEveryone(ctx)
+	if err != nil {
+		return err
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		names, errc := lines(ctx, e.stdin, args)
+		for name := range names {
+			// on error, Recv returns the status of the stream
+			if err := stream.Send(&greetpb.GreetEveryoneRequest{Greeting: greeting(name)}); err != nil {
+				return
+			}
+		}
+		if err := <-errc; err != nil {
+			readErr <- err
+			cancel()
+			return
+		}
+		stream.CloseSend()
+	}()
+
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			select {
+			case rerr := <-readErr:
+				return rerr
+			default:
+				return err
+			}
+		}
+		if err := e.out.print(res.GetResult(), result{res.GetResult()}); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Command tutoctl calls the RPCs of the greet and calculator services.
+//
+//	tutoctl [flags] greet <first name> [last name]
+//	tutoctl [flags] sum <a> <b>
+//	echo "12 4 23" | tutoctl -o json average
+//
+// The commands streaming names or numbers read them from their arguments,
+// or from the standard input, one name by line, as it is read: the
+// interactive commands greet-everyone and max print the responses as they
+// are received. The results are printed as text, or as JSON objects, one
+// by line (-o json). The exit code is 0 on success, 2 on a usage error, 1
+// on a local error and 10 + the gRPC status code on the error of a call,
+// e.g. 4 + 10 for DeadlineExceeded.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pjserol/tuto-grpc-go/calculator/calculatorclient"
+	"github.com/pjserol/tuto-grpc-go/cmd/internal/cli"
+	"github.com/pjserol/tuto-grpc-go/greet/greetpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// env is what the commands use.
+type env struct {
+	greet greetpb.GreetServiceClient
+	calc  *calculatorclient.Client
+	out   *output
+	stdin io.Reader
+}
+
+// commandFunc runs a command with the arguments following its name.
+type commandFunc func(ctx context.Context, e *env, args []string) error
+
+var commands = map[string]commandFunc{
+	"greet":               runGreet,
+	"greet-many":          runGreetMany,
+	"long-greet":          runLongGreet,
+	"greet-everyone":      runGreetEveryone,
+	"greet-with-deadline": runGreetWithDeadline,
+	"sum":                 runSum,
+	"factor":              runFactor,
+	"average":             runAverage,
+	"max":                 runMax,
+	"sqrt":                runSqrt,
+}
+
+// usages are the usages of the commands, in the order they are printed.
+var usages = cli.Usages{
+	{"greet", "greet <first name> [last name]"},
+	{"greet-many", "greet-many <first name> [last name]"},
+	{"long-greet", "long-greet [name...], or one name by line on stdin"},
+	{"greet-everyone", "greet-everyone [name...], or one name by line on stdin"},
+	{"greet-with-deadline", "greet-with-deadline <first name> [last name], slow: try -timeout 1s"},
+	{"sum", "sum <a> <b>"},
+	{"factor", "factor <n>"},
+	{"average", "average [number...], or the numbers of stdin"},
+	{"max", "max [number...], or the numbers of stdin"},
+	{"sqrt", "sqrt <n>"},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("tutoctl", flag.ContinueOnError)
+	conn := cli.AddConnFlags(flags, "ADDR")
+	timeout := flags.Duration("timeout", 0, "deadline of the command, none when zero")
+	format := flags.String("o", "text", "output format: text or json, one object by line")
+	flags.Usage = usages.Usage(flags, "tutoctl [flags] <command> [args]")
+	if err := flags.Parse(args); err != nil {
+		return cli.ExitUsage
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		if flags.NArg() > 0 {
+			fmt.Fprintf(os.Stderr, "tutoctl: unknown command %q\n", flags.Arg(0))
+		}
+		flags.Usage()
+		return cli.ExitUsage
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "tutoctl: unknown output format %q, expected text or json\n", *format)
+		return cli.ExitUsage
+	}
+
+	opts, err := conn.DialOptions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tutoctl: %v\n", err)
+		return cli.ExitError
+	}
+	cc, err := grpc.Dial(conn.Addr, opts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tutoctl: cannot connect to %s: %v\n", conn.Addr, err)
+		return cli.ExitError
+	}
+	defer cc.Close()
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	e := &env{
+		greet: greetpb.NewGreetServiceClient(cc),
+		calc:  calculatorclient.New(cc, calculatorclient.Options{}),
+		out:   &output{json: *format == "json", w: bufio.NewWriter(os.Stdout)},
+		stdin: os.Stdin,
+	}
+	err = cmd(ctx, e, flags.Args()[1:])
+	if ferr := e.out.w.Flush(); err == nil {
+		err = ferr
+	}
+	if err == cli.ErrUsage {
+		fmt.Fprintf(os.Stderr, "Usage: tutoctl [flags] %s\n", usages.Of(flags.Arg(0)))
+	}
+	return cli.ExitCode("tutoctl", err)
+}
+
+// output prints the results, as text or as JSON objects, one by line.
+type output struct {
+	json bool
+	w    *bufio.Writer
+}
+
+// print prints a result, text or v, and flushes it so that the results of
+// the streams are printed as they are received.
+func (o *output) print(text string, v interface{}) error {
+	if o.json {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+	if _, err := fmt.Fprintln(o.w, text); err != nil {
+		return err
+	}
+	return o.w.Flush()
+}
+
+// lines sends the non-empty lines of r, or args when not empty, on the
+// returned channel, closed at the end of the input, or when ctx is done
+// even while r is read. The error channel then receives the error of r or
+// the status of ctx.
+func lines(ctx context.Context, r io.Reader, args []string) (<-chan string, <-chan error) {
+	read := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(read)
+		if len(args) > 0 {
+			for _, arg := range args {
+				read <- arg
+			}
+			readErr <- nil
+			return
+		}
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if line := scanner.Text(); line != "" {
+				read <- line
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	ch := make(chan string)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				// the reading goroutine is left blocked until the end
+				// of the process
+				errc <- status.FromContextError(ctx.Err()).Err()
+				return
+			case line, ok := <-read:
+				if !ok {
+					errc <- <-readErr
+					return
+				}
+				select {
+				case ch <- line:
+				case <-ctx.Done():
+					errc <- status.FromContextError(ctx.Err()).Err()
+					return
+				}
+			}
+		}
+	}()
+	return ch, errc
+}
@@ -1,10 +1,26 @@
 #!/bin/bash
 
+# Connect handlers (greetpbconnect, ...) are generated with
+# go install connectrpc.com/connect/cmd/protoc-gen-connect-go
+# the M option gives the import path missing from the go_package option
+
 # greet
-protoc greet/greetpb/greet.proto --go_out=plugins=grpc:.
+protoc greet/greetpb/greet.proto --go_out=plugins=grpc:. \
+  --connect-go_out=paths=source_relative,Mgreet/greetpb/greet.proto=github.com/pjserol/tuto-grpc-go/greet/greetpb:.
 
 # calculator
-protoc calculator/calculatorpb/calculator.proto --go_out=plugins=grpc:.
+protoc -I . -I third_party/googleapis calculator/calculatorpb/calculator.proto --go_out=plugins=grpc:. \
+  --connect-go_out=paths=source_relative,Mcalculator/calculatorpb/calculator.proto=github.com/pjserol/tuto-grpc-go/calculator/calculatorpb:.
 
 # blog
-protoc blog/blogpb/blog.proto --go_out=plugins=grpc:.
\ No newline at end of file
+# go get github.com/grpc-ecosystem/grpc-gateway/protoc-gen-grpc-gateway
+protoc -I . -I third_party/googleapis blog/blogpb/blog.proto --go_out=plugins=grpc:. \
+  --grpc-gateway_out=logtostderr=true,paths=source_relative:. \
+  --connect-go_out=paths=source_relative,Mblog/blogpb/blog.proto=github.com/pjserol/tuto-grpc-go/blog/blogpb:.
+
+# OpenAPI v2 documents, served by the apidocs package
+# go get github.com/grpc-ecosystem/grpc-gateway/protoc-gen-swagger
+for proto in greet/greetpb/greet.proto calculator/calculatorpb/calculator.proto blog/blogpb/blog.proto; do
+  protoc -I . -I third_party/googleapis $proto \
+    --swagger_out=logtostderr=true,generate_unbound_methods=true:apidocs
+done
\ No newline at end of file
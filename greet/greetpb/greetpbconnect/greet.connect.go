@@ -0,0 +1,233 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: greet/greetpb/greet.proto
+
+package greetpbconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	greetpb "github.com/pjserol/tuto-grpc-go/greet/greetpb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// GreetServiceName is the fully-qualified name of the GreetService service.
+	GreetServiceName = "greet.GreetService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// GreetServiceGreetProcedure is the fully-qualified name of the GreetService's Greet RPC.
+	GreetServiceGreetProcedure = "/greet.GreetService/Greet"
+	// GreetServiceGreetManyTimesProcedure is the fully-qualified name of the GreetService's
+	// GreetManyTimes RPC.
+	GreetServiceGreetManyTimesProcedure = "/greet.GreetService/GreetManyTimes"
+	// GreetServiceLongGreetProcedure is the fully-qualified name of the GreetService's LongGreet RPC.
+	GreetServiceLongGreetProcedure = "/greet.GreetService/LongGreet"
+	// GreetServiceGreetEveryoneProcedure is the fully-qualified name of the GreetService's
+	// GreetEveryone RPC.
+	GreetServiceGreetEveryoneProcedure = "/greet.GreetService/GreetEveryone"
+	// GreetServiceGreetWithDeadlineProcedure is the fully-qualified name of the GreetService's
+	// GreetWithDeadline RPC.
+	GreetServiceGreetWithDeadlineProcedure = "/greet.GreetService/GreetWithDeadline"
+)
+
+// GreetServiceClient is a client for the greet.GreetService service.
+type GreetServiceClient interface {
+	// Unary
+	Greet(context.Context, *connect.Request[greetpb.GreetRequest]) (*connect.Response[greetpb.GreetResponse], error)
+	// Server Streaming
+	GreetManyTimes(context.Context, *connect.Request[greetpb.GreetManyTimesRequest]) (*connect.ServerStreamForClient[greetpb.GreetManyTimesResponse], error)
+	// Client Streaminng
+	LongGreet(context.Context) *connect.ClientStreamForClient[greetpb.LongGreetRequest, greetpb.LongGreetResponse]
+	// Bi-Directional Streaming
+	GreetEveryone(context.Context) *connect.BidiStreamForClient[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse]
+	// Unary with Deadline
+	GreetWithDeadline(context.Context, *connect.Request[greetpb.GreetWithDeadlineRequest]) (*connect.Response[greetpb.GreetWithDeadlineResponse], error)
+}
+
+// NewGreetServiceClient constructs a client for the greet.GreetService service. By default, it uses
+// the Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewGreetServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) GreetServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	greetServiceMethods := greetpb.File_greet_greetpb_greet_proto.Services().ByName("GreetService").Methods()
+	return &greetServiceClient{
+		greet: connect.NewClient[greetpb.GreetRequest, greetpb.GreetResponse](
+			httpClient,
+			baseURL+GreetServiceGreetProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("Greet")),
+			connect.WithClientOptions(opts...),
+		),
+		greetManyTimes: connect.NewClient[greetpb.GreetManyTimesRequest, greetpb.GreetManyTimesResponse](
+			httpClient,
+			baseURL+GreetServiceGreetManyTimesProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("GreetManyTimes")),
+			connect.WithClientOptions(opts...),
+		),
+		longGreet: connect.NewClient[greetpb.LongGreetRequest, greetpb.LongGreetResponse](
+			httpClient,
+			baseURL+GreetServiceLongGreetProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("LongGreet")),
+			connect.WithClientOptions(opts...),
+		),
+		greetEveryone: connect.NewClient[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse](
+			httpClient,
+			baseURL+GreetServiceGreetEveryoneProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("GreetEveryone")),
+			connect.WithClientOptions(opts...),
+		),
+		greetWithDeadline: connect.NewClient[greetpb.GreetWithDeadlineRequest, greetpb.GreetWithDeadlineResponse](
+			httpClient,
+			baseURL+GreetServiceGreetWithDeadlineProcedure,
+			connect.WithSchema(greetServiceMethods.ByName("GreetWithDeadline")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// greetServiceClient implements GreetServiceClient.
+type greetServiceClient struct {
+	greet             *connect.Client[greetpb.GreetRequest, greetpb.GreetResponse]
+	greetManyTimes    *connect.Client[greetpb.GreetManyTimesRequest, greetpb.GreetManyTimesResponse]
+	longGreet         *connect.Client[greetpb.LongGreetRequest, greetpb.LongGreetResponse]
+	greetEveryone     *connect.Client[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse]
+	greetWithDeadline *connect.Client[greetpb.GreetWithDeadlineRequest, greetpb.GreetWithDeadlineResponse]
+}
+
+// Greet calls greet.GreetService.Greet.
+func (c *greetServiceClient) Greet(ctx context.Context, req *connect.Request[greetpb.GreetRequest]) (*connect.Response[greetpb.GreetResponse], error) {
+	return c.greet.CallUnary(ctx, req)
+}
+
+// GreetManyTimes calls greet.GreetService.GreetManyTimes.
+func (c *greetServiceClient) GreetManyTimes(ctx context.Context, req *connect.Request[greetpb.GreetManyTimesRequest]) (*connect.ServerStreamForClient[greetpb.GreetManyTimesResponse], error) {
+	return c.greetManyTimes.CallServerStream(ctx, req)
+}
+
+// LongGreet calls greet.GreetService.LongGreet.
+func (c *greetServiceClient) LongGreet(ctx context.Context) *connect.ClientStreamForClient[greetpb.LongGreetRequest, greetpb.LongGreetResponse] {
+	return c.longGreet.CallClientStream(ctx)
+}
+
+// GreetEveryone calls greet.GreetService.GreetEveryone.
+func (c *greetServiceClient) GreetEveryone(ctx context.Context) *connect.BidiStreamForClient[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse] {
+	return c.greetEveryone.CallBidiStream(ctx)
+}
+
+// GreetWithDeadline calls greet.GreetService.GreetWithDeadline.
+func (c *greetServiceClient) GreetWithDeadline(ctx context.Context, req *connect.Request[greetpb.GreetWithDeadlineRequest]) (*connect.Response[greetpb.GreetWithDeadlineResponse], error) {
+	return c.greetWithDeadline.CallUnary(ctx, req)
+}
+
+// GreetServiceHandler is an implementation of the greet.GreetService service.
+type GreetServiceHandler interface {
+	// Unary
+	Greet(context.Context, *connect.Request[greetpb.GreetRequest]) (*connect.Response[greetpb.GreetResponse], error)
+	// Server Streaming
+	GreetManyTimes(context.Context, *connect.Request[greetpb.GreetManyTimesRequest], *connect.ServerStream[greetpb.GreetManyTimesResponse]) error
+	// Client Streaminng
+	LongGreet(context.Context, *connect.ClientStream[greetpb.LongGreetRequest]) (*connect.Response[greetpb.LongGreetResponse], error)
+	// Bi-Directional Streaming
+	GreetEveryone(context.Context, *connect.BidiStream[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse]) error
+	// Unary with Deadline
+	GreetWithDeadline(context.Context, *connect.Request[greetpb.GreetWithDeadlineRequest]) (*connect.Response[greetpb.GreetWithDeadlineResponse], error)
+}
+
+// NewGreetServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewGreetServiceHandler(svc GreetServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	greetServiceMethods := greetpb.File_greet_greetpb_greet_proto.Services().ByName("GreetService").Methods()
+	greetServiceGreetHandler := connect.NewUnaryHandler(
+		GreetServiceGreetProcedure,
+		svc.Greet,
+		connect.WithSchema(greetServiceMethods.ByName("Greet")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceGreetManyTimesHandler := connect.NewServerStreamHandler(
+		GreetServiceGreetManyTimesProcedure,
+		svc.GreetManyTimes,
+		connect.WithSchema(greetServiceMethods.ByName("GreetManyTimes")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceLongGreetHandler := connect.NewClientStreamHandler(
+		GreetServiceLongGreetProcedure,
+		svc.LongGreet,
+		connect.WithSchema(greetServiceMethods.ByName("LongGreet")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceGreetEveryoneHandler := connect.NewBidiStreamHandler(
+		GreetServiceGreetEveryoneProcedure,
+		svc.GreetEveryone,
+		connect.WithSchema(greetServiceMethods.ByName("GreetEveryone")),
+		connect.WithHandlerOptions(opts...),
+	)
+	greetServiceGreetWithDeadlineHandler := connect.NewUnaryHandler(
+		GreetServiceGreetWithDeadlineProcedure,
+		svc.GreetWithDeadline,
+		connect.WithSchema(greetServiceMethods.ByName("GreetWithDeadline")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/greet.GreetService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case GreetServiceGreetProcedure:
+			greetServiceGreetHandler.ServeHTTP(w, r)
+		case GreetServiceGreetManyTimesProcedure:
+			greetServiceGreetManyTimesHandler.ServeHTTP(w, r)
+		case GreetServiceLongGreetProcedure:
+			greetServiceLongGreetHandler.ServeHTTP(w, r)
+		case GreetServiceGreetEveryoneProcedure:
+			greetServiceGreetEveryoneHandler.ServeHTTP(w, r)
+		case GreetServiceGreetWithDeadlineProcedure:
+			greetServiceGreetWithDeadlineHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedGreetServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedGreetServiceHandler struct{}
+
+func (UnimplementedGreetServiceHandler) Greet(context.Context, *connect.Request[greetpb.GreetRequest]) (*connect.Response[greetpb.GreetResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.GreetService.Greet is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) GreetManyTimes(context.Context, *connect.Request[greetpb.GreetManyTimesRequest], *connect.ServerStream[greetpb.GreetManyTimesResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("greet.GreetService.GreetManyTimes is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) LongGreet(context.Context, *connect.ClientStream[greetpb.LongGreetRequest]) (*connect.Response[greetpb.LongGreetResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.GreetService.LongGreet is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) GreetEveryone(context.Context, *connect.BidiStream[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("greet.GreetService.GreetEveryone is not implemented"))
+}
+
+func (UnimplementedGreetServiceHandler) GreetWithDeadline(context.Context, *connect.Request[greetpb.GreetWithDeadlineRequest]) (*connect.Response[greetpb.GreetWithDeadlineResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("greet.GreetService.GreetWithDeadline is not implemented"))
+}
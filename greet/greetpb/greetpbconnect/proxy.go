@@ -0,0 +1,48 @@
+package greetpbconnect
+
+import (
+	"context"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/pjserol/tuto-grpc-go/greet/greetpb"
+	"github.com/pjserol/tuto-grpc-go/httpmux"
+	"google.golang.org/grpc"
+)
+
+// NewGreetServiceProxyHandler returns the path and the Connect handler of
+// the GreetService, proxying the calls to the gRPC server of cc. It is a
+// httpmux.ConnectService.
+func NewGreetServiceProxyHandler(cc grpc.ClientConnInterface) (string, http.Handler) {
+	return NewGreetServiceHandler(&greetServiceProxy{client: greetpb.NewGreetServiceClient(cc)})
+}
+
+type greetServiceProxy struct {
+	client greetpb.GreetServiceClient
+}
+
+func (p *greetServiceProxy) Greet(ctx context.Context, req *connect.Request[greetpb.GreetRequest]) (*connect.Response[greetpb.GreetResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.Greet)
+}
+
+func (p *greetServiceProxy) GreetManyTimes(ctx context.Context, req *connect.Request[greetpb.GreetManyTimesRequest], stream *connect.ServerStream[greetpb.GreetManyTimesResponse]) error {
+	return httpmux.ProxyServerStream(ctx, req, stream, func(ctx context.Context, req *greetpb.GreetManyTimesRequest) (httpmux.Receiver[greetpb.GreetManyTimesResponse], error) {
+		return p.client.GreetManyTimes(ctx, req)
+	})
+}
+
+func (p *greetServiceProxy) LongGreet(ctx context.Context, stream *connect.ClientStream[greetpb.LongGreetRequest]) (*connect.Response[greetpb.LongGreetResponse], error) {
+	return httpmux.ProxyClientStream(ctx, stream, func(ctx context.Context) (httpmux.ClientStreamer[greetpb.LongGreetRequest, greetpb.LongGreetResponse], error) {
+		return p.client.LongGreet(ctx)
+	})
+}
+
+func (p *greetServiceProxy) GreetEveryone(ctx context.Context, stream *connect.BidiStream[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse]) error {
+	return httpmux.ProxyBidiStream(ctx, stream, func(ctx context.Context) (httpmux.BidiStreamer[greetpb.GreetEveryoneRequest, greetpb.GreetEveryoneResponse], error) {
+		return p.client.GreetEveryone(ctx)
+	})
+}
+
+func (p *greetServiceProxy) GreetWithDeadline(ctx context.Context, req *connect.Request[greetpb.GreetWithDeadlineRequest]) (*connect.Response[greetpb.GreetWithDeadlineResponse], error) {
+	return httpmux.ProxyUnary(ctx, req, p.client.GreetWithDeadline)
+}
@@ -0,0 +1,17 @@
+package greetpb
+
+import "github.com/pjserol/tuto-grpc-go/validation"
+
+var greetingRules = validation.Rules{
+	"greeting":           {Required: true},
+	"greeting.fist_name": {Required: true, MaxLen: 100},
+	"greeting.last_name": {MaxLen: 100},
+}
+
+func init() {
+	validation.Register(&GreetRequest{}, greetingRules)
+	validation.Register(&GreetManyTimesRequest{}, greetingRules)
+	validation.Register(&LongGreetRequest{}, greetingRules)
+	validation.Register(&GreetEveryoneRequest{}, greetingRules)
+	validation.Register(&GreetWithDeadlineRequest{}, greetingRules)
+}
@@ -0,0 +1,100 @@
+// Package greetserver implements the GreetService.
+package greetserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/greet/greetpb"
+	"github.com/pjserol/tuto-grpc-go/greet/greetpb/greetpbconnect"
+	"github.com/pjserol/tuto-grpc-go/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements greetpb.GreetServiceServer.
+type Server struct{}
+
+// Service returns the GreetService, to be run by the server package.
+func Service() server.Service {
+	return server.Service{
+		Name: "greet",
+		Register: func(s *grpc.Server) {
+			greetpb.RegisterGreetServiceServer(s, &Server{})
+		},
+		Connect: greetpbconnect.NewGreetServiceProxyHandler,
+	}
+}
+
+func (*Server) Greet(ctx context.Context, req *greetpb.GreetRequest) (*greetpb.GreetResponse, error) {
+	res := fmt.Sprintf("Hello, %s %s", req.GetGreeting().FistName, req.GetGreeting().LastName)
+	return &greetpb.GreetResponse{
+		Result: res,
+	}, nil
+}
+
+func (*Server) GreetManyTimes(req *greetpb.GreetManyTimesRequest, stream greetpb.GreetService_GreetManyTimesServer) error {
+	for i := 0; i < 5; i++ {
+		stream.Send(
+			&greetpb.GreetManyTimesResponse{
+				Result: fmt.Sprintf("Hello, %s %s number %d", req.GetGreeting().FistName, req.GetGreeting().LastName, i),
+			},
+		)
+		time.Sleep(1 * time.Second)
+	}
+	return nil
+}
+
+func (*Server) LongGreet(stream greetpb.GreetService_LongGreetServer) error {
+	result := ""
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			// we've reached the end of the client stream
+			return stream.SendAndClose(&greetpb.LongGreetResponse{
+				Result: result,
+			})
+		} else if err != nil {
+			return err
+		}
+		res := fmt.Sprintf("Hello, %s %s!", msg.GetGreeting().FistName, msg.GetGreeting().LastName)
+		result += res + "\n"
+	}
+}
+
+// Bi-Directional Streaming
+func (*Server) GreetEveryone(stream greetpb.GreetService_GreetEveryoneServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := stream.Send(&greetpb.GreetEveryoneResponse{
+			Result: fmt.Sprintf("Hello, %s %s", req.GetGreeting().GetFistName(), req.GetGreeting().GetLastName()),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func (*Server) GreetWithDeadline(ctx context.Context, req *greetpb.GreetWithDeadlineRequest) (*greetpb.GreetWithDeadlineResponse, error) {
+	for i := 0; i < 3; i++ {
+		if ctx.Err() == context.Canceled {
+			// the client canceled the request
+			return nil, status.Error(codes.Canceled, "The client canclled the request!")
+		}
+		time.Sleep(1 * time.Second)
+	}
+	res := fmt.Sprintf("Hello, %s %s", req.GetGreeting().FistName, req.GetGreeting().LastName)
+	return &greetpb.GreetWithDeadlineResponse{
+		Result: res,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package grpcerr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is the error of a call on the client side, with the details sent by
+// the server. The errors of a call whose context is done also match
+// context.Canceled or context.DeadlineExceeded.
+//
+// The clients declare the errors matched by errors.Is on their Code, e.g.
+//
+//	var ErrNotFound = &grpcerr.Error{Code: codes.NotFound}
+type Error struct {
+	Code    codes.Code
+	Message string
+	// Reason is the reason of the ErrorInfo detail, e.g. ReasonNotFound.
+	Reason string
+	// ResourceType and ResourceName identify the resource not found or
+	// already existing, e.g. "blog" and its id.
+	ResourceType string
+	ResourceName string
+	// Violations are the fields of the request rejected by an
+	// InvalidArgument or OutOfRange error.
+	Violations []Violation
+	// RetryDelay is how long to wait before retrying the call, when the
+	// server told it.
+	RetryDelay time.Duration
+
+	status *status.Status
+}
+
+// Violation is a field of the request and why it was rejected.
+type Violation struct {
+	Field       string
+	Description string
+}
+
+// ClientError returns the *Error of err, the error of a call, or nil when
+// err is nil.
+func ClientError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st := status.Convert(err)
+	e := &Error{
+		Code:    st.Code(),
+		Message: st.Message(),
+		Reason:  Reason(err),
+		status:  st,
+	}
+	if info, ok := ResourceInfo(err); ok {
+		e.ResourceType = info.GetResourceType()
+		e.ResourceName = info.GetResourceName()
+	}
+	if req, ok := BadRequest(err); ok {
+		for _, v := range req.GetFieldViolations() {
+			e.Violations = append(e.Violations, Violation{Field: v.GetField(), Description: v.GetDescription()})
+		}
+	}
+	if delay, ok := RetryDelay(err); ok {
+		e.RetryDelay = delay
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rpc error: code = %s desc = %s", e.Code, e.Message)
+}
+
+// GRPCStatus returns the status of the error, so that status.FromError and
+// the helpers above accept it.
+func (e *Error) GRPCStatus() *status.Status {
+	if e.status == nil {
+		return status.New(e.Code, e.Message)
+	}
+	return e.status
+}
+
+// Is matches the errors declared by the clients with the same Code, and
+// the context errors.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case context.Canceled:
+		return e.Code == codes.Canceled
+	case context.DeadlineExceeded:
+		return e.Code == codes.DeadlineExceeded
+	}
+
+	t, ok := target.(*Error)
+	return ok && t.status == nil && t.Code == e.Code
+}
@@ -0,0 +1,103 @@
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientError(t *testing.T) {
+	errNotFound := &Error{Code: codes.NotFound}
+
+	tests := []struct {
+		name  string
+		err   error
+		is    []error
+		isNot []error
+		check func(t *testing.T, e *Error)
+	}{
+		{
+			name:  "not found",
+			err:   NotFound("blog", "42"),
+			is:    []error{errNotFound},
+			isNot: []error{&Error{Code: codes.InvalidArgument}, context.Canceled},
+			check: func(t *testing.T, e *Error) {
+				if e.Reason != ReasonNotFound || e.ResourceType != "blog" || e.ResourceName != "42" {
+					t.Errorf("reason, resource = %q, %q %q", e.Reason, e.ResourceType, e.ResourceName)
+				}
+			},
+		},
+		{
+			name: "invalid argument",
+			err:  InvalidArgument("blog.title", "must not be empty"),
+			is:   []error{&Error{Code: codes.InvalidArgument}},
+			check: func(t *testing.T, e *Error) {
+				if len(e.Violations) != 1 || e.Violations[0] != (Violation{Field: "blog.title", Description: "must not be empty"}) {
+					t.Errorf("violations = %v", e.Violations)
+				}
+			},
+		},
+		{
+			name: "unavailable",
+			err:  Unavailable("down", 5*time.Second),
+			check: func(t *testing.T, e *Error) {
+				if e.RetryDelay != 5*time.Second {
+					t.Errorf("retry delay = %v, want 5s", e.RetryDelay)
+				}
+				if d, ok := RetryDelay(e); !ok || d != 5*time.Second {
+					t.Errorf("RetryDelay(*Error) = %v, %v: the details must be kept", d, ok)
+				}
+			},
+		},
+		{
+			name:  "canceled",
+			err:   status.FromContextError(context.Canceled).Err(),
+			is:    []error{context.Canceled},
+			isNot: []error{context.DeadlineExceeded},
+		},
+		{
+			name: "deadline exceeded",
+			err:  status.FromContextError(context.DeadlineExceeded).Err(),
+			is:   []error{context.DeadlineExceeded},
+		},
+		{
+			name:  "not a sentinel",
+			err:   NotFound("blog", "42"),
+			isNot: []error{ClientError(NotFound("blog", "42"))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClientError(tt.err)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("ClientError() = %T, want *Error", err)
+			}
+			if e.Code != status.Code(tt.err) || status.Code(err) != status.Code(tt.err) {
+				t.Errorf("code = %v, status code %v, want %v", e.Code, status.Code(err), status.Code(tt.err))
+			}
+			for _, target := range tt.is {
+				if !errors.Is(err, target) {
+					t.Errorf("errors.Is(%v) = false", target)
+				}
+			}
+			for _, target := range tt.isNot {
+				if errors.Is(err, target) {
+					t.Errorf("errors.Is(%v) = true", target)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+
+	if ClientError(nil) != nil {
+		t.Error("ClientError(nil) != nil")
+	}
+}
@@ -0,0 +1,194 @@
+// Package grpcerr builds the status errors returned by the servers, with
+// google.rpc error details attached, and extracts these details on the
+// client side.
+package grpcerr
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is the ErrorInfo domain of the errors of the services.
+const Domain = "tuto-grpc-go"
+
+// Reasons set in the ErrorInfo detail.
+const (
+	ReasonNotFound        = "NOT_FOUND"
+	ReasonAlreadyExists   = "ALREADY_EXISTS"
+	ReasonAborted         = "ABORTED"
+	ReasonUnavailable     = "UNAVAILABLE"
+	ReasonInvalidArgument = "INVALID_ARGUMENT"
+	ReasonOutOfRange      = "OUT_OF_RANGE"
+	ReasonPrecondition    = "FAILED_PRECONDITION"
+	ReasonRateLimited     = "RATE_LIMITED"
+	ReasonQueueFull       = "QUEUE_FULL"
+	ReasonInternal        = "INTERNAL"
+)
+
+// New returns an error with the code and message, and an ErrorInfo detail
+// with the reason and metadata, followed by the other details.
+func New(code codes.Code, reason string, msg string, metadata map[string]string, details ...protoiface.MessageV1) error {
+	st := status.New(code, msg)
+	details = append([]protoiface.MessageV1{&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   Domain,
+		Metadata: metadata,
+	}}, details...)
+
+	// WithDetails only fails for the OK code
+	if detailed, err := st.WithDetails(details...); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// NotFound returns a NotFound error for the resource of the type and name.
+func NotFound(resourceType string, name string) error {
+	return New(codes.NotFound, ReasonNotFound, "Cannot find "+resourceType+" "+name, nil,
+		&errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: name, Description: "not found"},
+	)
+}
+
+// AlreadyExists returns an AlreadyExists error for the resource of the type
+// and name.
+func AlreadyExists(resourceType string, name string) error {
+	return New(codes.AlreadyExists, ReasonAlreadyExists, resourceType+" "+name+" already exists", nil,
+		&errdetails.ResourceInfo{ResourceType: resourceType, ResourceName: name, Description: "already exists"},
+	)
+}
+
+// Aborted returns an Aborted error telling the client to retry the whole
+// operation after delay.
+func Aborted(msg string, delay time.Duration) error {
+	return New(codes.Aborted, ReasonAborted, msg, nil,
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)},
+	)
+}
+
+// Unavailable returns an Unavailable error telling the client to retry
+// after delay.
+func Unavailable(msg string, delay time.Duration) error {
+	return New(codes.Unavailable, ReasonUnavailable, msg, nil,
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)},
+	)
+}
+
+// ResourceExhausted returns a ResourceExhausted error telling the client to
+// retry after delay.
+func ResourceExhausted(reason string, msg string, delay time.Duration) error {
+	return New(codes.ResourceExhausted, reason, msg, nil,
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)},
+	)
+}
+
+// InvalidArgument returns an InvalidArgument error with a BadRequest
+// detail for the field.
+func InvalidArgument(field string, description string) error {
+	return New(codes.InvalidArgument, ReasonInvalidArgument, "Invalid "+field+": "+description,
+		map[string]string{"field": field},
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: description,
+		}}},
+	)
+}
+
+// OutOfRange returns an OutOfRange error with a BadRequest detail for the
+// field, for a valid value whose result cannot be represented.
+func OutOfRange(field string, description string) error {
+	return New(codes.OutOfRange, ReasonOutOfRange, "Out of range "+field+": "+description,
+		map[string]string{"field": field},
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: description,
+		}}},
+	)
+}
+
+// FailedPrecondition returns a FailedPrecondition error with a
+// PreconditionFailure detail, for a call that cannot succeed until the
+// state of the server changes, e.g. "CONFIG" "currency rates".
+func FailedPrecondition(violationType string, subject string, description string) error {
+	return New(codes.FailedPrecondition, ReasonPrecondition, "Failed precondition "+subject+": "+description, nil,
+		&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        violationType,
+			Subject:     subject,
+			Description: description,
+		}}},
+	)
+}
+
+// Internal returns an Internal error. msg is sent to the client, so it
+// must not contain the text of the underlying error.
+func Internal(msg string) error {
+	return New(codes.Internal, ReasonInternal, msg, nil)
+}
+
+// ErrorInfo returns the ErrorInfo detail of err.
+func ErrorInfo(err error) (*errdetails.ErrorInfo, bool) {
+	info := &errdetails.ErrorInfo{}
+	return info, detail(err, info)
+}
+
+// ResourceInfo returns the ResourceInfo detail of err.
+func ResourceInfo(err error) (*errdetails.ResourceInfo, bool) {
+	info := &errdetails.ResourceInfo{}
+	return info, detail(err, info)
+}
+
+// RetryInfo returns the RetryInfo detail of err.
+func RetryInfo(err error) (*errdetails.RetryInfo, bool) {
+	info := &errdetails.RetryInfo{}
+	return info, detail(err, info)
+}
+
+// BadRequest returns the BadRequest detail of err.
+func BadRequest(err error) (*errdetails.BadRequest, bool) {
+	info := &errdetails.BadRequest{}
+	return info, detail(err, info)
+}
+
+// PreconditionFailure returns the PreconditionFailure detail of err.
+func PreconditionFailure(err error) (*errdetails.PreconditionFailure, bool) {
+	info := &errdetails.PreconditionFailure{}
+	return info, detail(err, info)
+}
+
+// RetryDelay returns how long to wait before retrying the call failed with
+// err, when the server told it.
+func RetryDelay(err error) (time.Duration, bool) {
+	info, ok := RetryInfo(err)
+	if !ok {
+		return 0, false
+	}
+	return info.GetRetryDelay().AsDuration(), true
+}
+
+// Reason returns the reason of the ErrorInfo detail of err, or an empty
+// string.
+func Reason(err error) string {
+	info, _ := ErrorInfo(err)
+	return info.GetReason()
+}
+
+// detail copies into dst the first detail of err of the same type.
+func detail(err error, dst proto.Message) bool {
+	var se interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &se) {
+		return false
+	}
+
+	for _, d := range se.GRPCStatus().Proto().GetDetails() {
+		if d.MessageIs(dst) {
+			return d.UnmarshalTo(dst) == nil
+		}
+	}
+	return false
+}
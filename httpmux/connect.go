@@ -0,0 +1,244 @@
+package httpmux
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/pjserol/tuto-grpc-go/interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// inProcessBufferSize is the size of the buffers of the in-process
+// connections.
+const inProcessBufferSize = 1 << 20
+
+// forwardedHeaders are the request headers of the Connect calls forwarded
+// to the gRPC server.
+var forwardedHeaders = []string{interceptor.RequestIDKey, "authorization"}
+
+// ConnectService returns the path and the Connect handler of a service,
+// proxying the calls to the gRPC server through cc, e.g.
+// greetpbconnect.NewGreetServiceProxyHandler. Going through the gRPC
+// server applies its interceptors to the Connect calls too.
+type ConnectService func(cc grpc.ClientConnInterface) (string, http.Handler)
+
+// Receiver is the client side of a gRPC server streaming call.
+type Receiver[Res any] interface {
+	Recv() (*Res, error)
+	grpc.ClientStream
+}
+
+// ClientStreamer is the client side of a gRPC client streaming call.
+type ClientStreamer[Req, Res any] interface {
+	Send(*Req) error
+	CloseAndRecv() (*Res, error)
+	grpc.ClientStream
+}
+
+// BidiStreamer is the client side of a gRPC bidirectional streaming call.
+type BidiStreamer[Req, Res any] interface {
+	Send(*Req) error
+	Recv() (*Res, error)
+	grpc.ClientStream
+}
+
+// dialInProcess serves s on an in-process listener and connects to it.
+func dialInProcess(s *grpc.Server) (*grpc.ClientConn, error) {
+	lis := bufconn.Listen(inProcessBufferSize)
+	go s.Serve(lis)
+
+	return grpc.Dial(interceptor.InProcessNetwork,
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+}
+
+// ProxyUnary forwards the unary Connect call req to the gRPC method call.
+func ProxyUnary[Req, Res any](ctx context.Context, req *connect.Request[Req], call func(context.Context, *Req, ...grpc.CallOption) (*Res, error)) (*connect.Response[Res], error) {
+	var header, trailer metadata.MD
+	msg, err := call(outgoingContext(ctx, req.Peer(), req.Header()), req.Msg, grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		return nil, connectError(err, header, trailer)
+	}
+
+	res := connect.NewResponse(msg)
+	copyMetadata(res.Header(), header)
+	copyMetadata(res.Trailer(), trailer)
+	return res, nil
+}
+
+// ProxyServerStream forwards the server streaming Connect call req to the
+// gRPC stream opened by open.
+func ProxyServerStream[Req, Res any](ctx context.Context, req *connect.Request[Req], out *connect.ServerStream[Res], open func(context.Context, *Req) (Receiver[Res], error)) error {
+	stream, err := open(outgoingContext(ctx, req.Peer(), req.Header()), req.Msg)
+	if err != nil {
+		return connectError(err, nil, nil)
+	}
+
+	header, err := stream.Header()
+	if err != nil {
+		return connectError(err, nil, stream.Trailer())
+	}
+	copyMetadata(out.ResponseHeader(), header)
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			copyMetadata(out.ResponseTrailer(), stream.Trailer())
+			return nil
+		} else if err != nil {
+			return connectError(err, nil, stream.Trailer())
+		}
+
+		if err := out.Send(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// ProxyClientStream forwards the client streaming Connect call in to the
+// gRPC stream opened by open.
+func ProxyClientStream[Req, Res any](ctx context.Context, in *connect.ClientStream[Req], open func(context.Context) (ClientStreamer[Req, Res], error)) (*connect.Response[Res], error) {
+	stream, err := open(outgoingContext(ctx, in.Peer(), in.RequestHeader()))
+	if err != nil {
+		return nil, connectError(err, nil, nil)
+	}
+
+	for in.Receive() {
+		if err := stream.Send(in.Msg()); err == io.EOF {
+			// the server ended the call, CloseAndRecv returns its status
+			break
+		} else if err != nil {
+			return nil, connectError(err, nil, nil)
+		}
+	}
+	if err := in.Err(); err != nil {
+		return nil, err
+	}
+
+	msg, err := stream.CloseAndRecv()
+	header, _ := stream.Header()
+	if err != nil {
+		return nil, connectError(err, header, stream.Trailer())
+	}
+
+	res := connect.NewResponse(msg)
+	copyMetadata(res.Header(), header)
+	copyMetadata(res.Trailer(), stream.Trailer())
+	return res, nil
+}
+
+// ProxyBidiStream forwards the bidirectional streaming Connect call bidi to
+// the gRPC stream opened by open.
+func ProxyBidiStream[Req, Res any](ctx context.Context, bidi *connect.BidiStream[Req, Res], open func(context.Context) (BidiStreamer[Req, Res], error)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := open(outgoingContext(ctx, bidi.Peer(), bidi.RequestHeader()))
+	if err != nil {
+		return connectError(err, nil, nil)
+	}
+
+	// forward the requests while the responses are received
+	sendErr := make(chan error, 1)
+	go func() {
+		for {
+			msg, err := bidi.Receive()
+			if errors.Is(err, io.EOF) {
+				sendErr <- stream.CloseSend()
+				return
+			} else if err != nil {
+				sendErr <- err
+				cancel()
+				return
+			}
+
+			if err := stream.Send(msg); err == io.EOF {
+				// the server ended the call, Recv returns its status
+				sendErr <- nil
+				return
+			} else if err != nil {
+				sendErr <- connectError(err, nil, nil)
+				return
+			}
+		}
+	}()
+
+	header, err := stream.Header()
+	if err != nil {
+		return connectError(err, nil, stream.Trailer())
+	}
+	copyMetadata(bidi.ResponseHeader(), header)
+
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return connectError(err, nil, stream.Trailer())
+		}
+
+		if err := bidi.Send(msg); err != nil {
+			return err
+		}
+	}
+	copyMetadata(bidi.ResponseTrailer(), stream.Trailer())
+	return <-sendErr
+}
+
+// outgoingContext returns ctx with the metadata sent to the gRPC server:
+// the forwarded headers and the address of the client.
+func outgoingContext(ctx context.Context, p connect.Peer, header http.Header) context.Context {
+	md := metadata.MD{}
+	for _, key := range forwardedHeaders {
+		if values := header.Values(key); len(values) > 0 {
+			md.Set(key, values...)
+		}
+	}
+	if p.Addr != "" {
+		md.Set(interceptor.ForwardedForKey, p.Addr)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// copyMetadata copies the metadata set by the gRPC server into dst,
+// without the ones of the gRPC protocol.
+func copyMetadata(dst http.Header, md metadata.MD) {
+	for key, values := range md {
+		if key == "content-type" || strings.HasPrefix(key, "grpc-") {
+			continue
+		}
+		for _, v := range values {
+			dst.Add(key, v)
+		}
+	}
+}
+
+// connectError converts the status error err of the gRPC server, with its
+// details and metadata, to a Connect error.
+func connectError(err error, header metadata.MD, trailer metadata.MD) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	cerr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, d := range st.Proto().GetDetails() {
+		if detail, err := connect.NewErrorDetail(d); err == nil {
+			cerr.AddDetail(detail)
+		}
+	}
+	copyMetadata(cerr.Meta(), header)
+	copyMetadata(cerr.Meta(), trailer)
+	return cerr
+}
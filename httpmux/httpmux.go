@@ -0,0 +1,141 @@
+// Package httpmux serves a grpc.Server over net/http, so that the same port
+// also accepts gRPC-Web requests from the browsers and Connect requests.
+package httpmux
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"github.com/pjserol/tuto-grpc-go/interceptor"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// webHeaders are the request headers sent by the gRPC-Web clients, and
+// the request ID read by the logging interceptor.
+var webHeaders = []string{"content-type", "x-grpc-web", "x-user-agent", "grpc-timeout", interceptor.RequestIDKey}
+
+// Config configures the protocols served next to gRPC.
+type Config struct {
+	// GRPCWeb enables gRPC-Web over HTTP/1.1 and HTTP/2.
+	GRPCWeb bool
+	// Connect enables the Connect protocol, with JSON and binary messages,
+	// for the services given to Serve.
+	Connect bool
+	// AllowedOrigins are the origins of the browser requests (CORS). "*"
+	// allows all of them.
+	AllowedOrigins []string
+	// AllowedHeaders are the request headers the browsers can send, on top
+	// of webHeaders.
+	AllowedHeaders []string
+}
+
+// ConfigFromEnv reads the Config from the GRPC_WEB and CONNECT (true to
+// enable gRPC-Web and Connect), CORS_ALLOWED_ORIGINS and
+// CORS_ALLOWED_HEADERS (comma separated lists) environment variables.
+func ConfigFromEnv() Config {
+	return Config{
+		GRPCWeb:        os.Getenv("GRPC_WEB") == "true",
+		Connect:        os.Getenv("CONNECT") == "true",
+		AllowedOrigins: splitList(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		AllowedHeaders: splitList(os.Getenv("CORS_ALLOWED_HEADERS")),
+	}
+}
+
+// Enabled reports whether the grpc.Server must be served by Handler
+// instead of grpc.Server.Serve.
+func (c Config) Enabled() bool {
+	return c.GRPCWeb || c.Connect
+}
+
+func (c Config) allowOrigin(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler serves the gRPC requests with s, the gRPC-Web requests
+// (including the server streaming ones) translated to gRPC when enabled,
+// and the other requests with connect when it is not nil.
+func Handler(s *grpc.Server, cfg Config, connect http.Handler) http.Handler {
+	var h http.Handler = s
+	if cfg.GRPCWeb {
+		h = grpcweb.WrapServer(s,
+			grpcweb.WithOriginFunc(cfg.allowOrigin),
+			grpcweb.WithAllowedRequestHeaders(append(webHeaders[:len(webHeaders):len(webHeaders)], cfg.AllowedHeaders...)),
+		)
+	}
+	if connect == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// gRPC and gRPC-Web requests, and the CORS preflight requests
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") || r.Method == http.MethodOptions {
+			h.ServeHTTP(w, r)
+			return
+		}
+		connect.ServeHTTP(w, r)
+	})
+}
+
+// Serve serves s on lis: with grpc.Server.Serve when cfg is not enabled,
+// with an HTTP server otherwise, using TLS when certFile and keyFile are
+// set. Without TLS, HTTP/2 is accepted in clear text (h2c) for the gRPC
+// clients. The Connect requests are served by services, when enabled. It
+// returns nil once lis is closed.
+func Serve(s *grpc.Server, lis net.Listener, cfg Config, certFile string, keyFile string, services ...ConnectService) error {
+	if !cfg.Enabled() {
+		return s.Serve(lis)
+	}
+
+	var connect http.Handler
+	if cfg.Connect && len(services) > 0 {
+		cc, err := dialInProcess(s)
+		if err != nil {
+			return err
+		}
+		defer cc.Close()
+
+		mux := http.NewServeMux()
+		for _, service := range services {
+			mux.Handle(service(cc))
+		}
+		connect = mux
+	}
+
+	srv := &http.Server{Handler: Handler(s, cfg, connect)}
+	var err error
+	if certFile != "" {
+		if err := http2.ConfigureServer(srv, &http2.Server{}); err != nil {
+			return err
+		}
+		err = srv.ServeTLS(lis, certFile, keyFile)
+	} else {
+		srv.Handler = h2c.NewHandler(srv.Handler, &http2.Server{})
+		err = srv.Serve(lis)
+	}
+
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,252 @@
+// Package interceptor contains the gRPC server interceptors shared by the
+// greet, calculator and blog servers.
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// RequestIDKey is the metadata key used to propagate the request ID
+// between the client and the server.
+const RequestIDKey = "x-request-id"
+
+const redactedValue = "[REDACTED]"
+
+type requestIDCtxKey struct{}
+
+// Logger writes one structured (JSON) log line per RPC.
+type Logger struct {
+	log    *slog.Logger
+	redact map[string]bool
+}
+
+// NewLogger creates a Logger writing JSON to w. Payloads are only logged
+// at debug level, with the value of every field listed in redact
+// (proto or JSON name) replaced.
+func NewLogger(w io.Writer, level slog.Level, redact ...string) *Logger {
+	l := &Logger{
+		log:    slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})),
+		redact: map[string]bool{},
+	}
+	for _, field := range redact {
+		if field = strings.TrimSpace(field); field != "" {
+			l.redact[field] = true
+		}
+	}
+	return l
+}
+
+// NewLoggerFromEnv creates a Logger writing to stdout, configured by the
+// LOG_LEVEL (debug, info, warn, error) and LOG_REDACT (comma separated
+// field names) environment variables. The redact fields are added to the
+// defaults given by the caller.
+func NewLoggerFromEnv(redact ...string) *Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv("LOG_LEVEL"))); err != nil {
+		level = slog.LevelInfo
+	}
+	redact = append(redact, strings.Split(os.Getenv("LOG_REDACT"), ",")...)
+	return NewLogger(os.Stdout, level, redact...)
+}
+
+// Slog returns the underlying logger, for the logs not tied to an RPC.
+func (l *Logger) Slog() *slog.Logger {
+	return l.log
+}
+
+// UnaryServerInterceptor logs every unary RPC once it has completed.
+func (l *Logger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = withRequestID(ctx)
+		l.payload(ctx, info.FullMethod, "request", req)
+
+		res, err := handler(ctx, req)
+
+		if err == nil {
+			l.payload(ctx, info.FullMethod, "response", res)
+		}
+		l.done(ctx, info.FullMethod, start, err)
+		return res, err
+	}
+}
+
+// StreamServerInterceptor logs every streaming RPC once it has completed,
+// and each message sent or received at debug level.
+func (l *Logger) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := withRequestID(ss.Context())
+
+		err := handler(srv, &loggingStream{
+			ServerStream: ss,
+			ctx:          ctx,
+			logger:       l,
+			method:       info.FullMethod,
+		})
+
+		l.done(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func (l *Logger) done(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	attrs := append(l.rpcAttrs(ctx, method),
+		slog.String("code", code.String()),
+		slog.Duration("duration", time.Since(start)),
+	)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	l.log.LogAttrs(ctx, levelForCode(code), "finished call", attrs...)
+}
+
+func (l *Logger) payload(ctx context.Context, method string, kind string, msg interface{}) {
+	if !l.log.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return
+	}
+	attrs := append(l.rpcAttrs(ctx, method), slog.Any("payload", l.redacted(m)))
+	l.log.LogAttrs(ctx, slog.LevelDebug, kind, attrs...)
+}
+
+func (l *Logger) rpcAttrs(ctx context.Context, method string) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("request_id", RequestIDFromContext(ctx)),
+	}
+	if addr := peerAddr(ctx); addr != "" {
+		attrs = append(attrs, slog.String("peer", addr))
+	}
+	return attrs
+}
+
+// redacted converts msg to a JSON like value with the redacted fields
+// replaced, so it can be logged as is.
+func (l *Logger) redacted(msg proto.Message) interface{} {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return err.Error()
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err.Error()
+	}
+	return l.redactValue(v)
+}
+
+func (l *Logger) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if l.redact[key] || l.redact[jsonName(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = l.redactValue(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = l.redactValue(value)
+		}
+	}
+	return v
+}
+
+// jsonName converts a proto field name to its lowerCamelCase JSON name.
+func jsonName(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func levelForCode(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented:
+		return slog.LevelError
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// RequestIDFromContext returns the ID of the request being served, or an
+// empty string when the context does not belong to an RPC.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// withRequestID stores in the context the request ID sent by the client,
+// or a new one, and sends it back in the response header.
+func withRequestID(ctx context.Context) context.Context {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return ctx
+	}
+
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+	return context.WithValue(ctx, requestIDCtxKey{}, id)
+}
+
+type loggingStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	logger *Logger
+	method string
+}
+
+func (s *loggingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *loggingStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.logger.payload(s.ctx, s.method, "response", m)
+	return nil
+}
+
+func (s *loggingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.logger.payload(s.ctx, s.method, "request", m)
+	return nil
+}
@@ -0,0 +1,25 @@
+package interceptor
+
+import (
+	"expvar"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// ServeMetricsFromEnv serves the expvar metrics (e.g. Panics) over HTTP on
+// /debug/vars when the METRICS_ADDR environment variable is set.
+func ServeMetricsFromEnv(log *slog.Logger) {
+	addr := os.Getenv("METRICS_ADDR")
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			log.Error("failed to serve metrics", slog.String("error", err.Error()))
+		}
+	}()
+}
@@ -0,0 +1,34 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ForwardedForKey is the metadata key carrying the address of the client
+// of a call proxied in process, e.g. by the Connect handlers of httpmux.
+const ForwardedForKey = "x-forwarded-for"
+
+// InProcessNetwork is the network of the in-process connections. The
+// ForwardedForKey metadata is only trusted on these connections.
+const InProcessNetwork = "bufconn"
+
+// peerAddr returns the address of the client of the call, or an empty
+// string.
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if p.Addr.Network() == InProcessNetwork {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(ForwardedForKey); len(values) > 0 {
+				return values[0]
+			}
+		}
+	}
+	return p.Addr.String()
+}
@@ -0,0 +1,251 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RetryAfterKey is the trailer key telling a rate limited client how many
+// seconds to wait before trying again.
+const RetryAfterKey = "retry-after"
+
+// limiterIdleTimeout is how long the state of a client is kept after its
+// last call.
+const limiterIdleTimeout = 10 * time.Minute
+
+// Limit is a token bucket: Rate requests per second, with bursts of up to
+// Burst requests. A zero Rate means no limit.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// RateLimitConfig configures the RateLimiter.
+type RateLimitConfig struct {
+	// Default is the limit of the methods not listed in Methods.
+	Default Limit `json:"default"`
+	// Methods are the limits by full method name,
+	// e.g. /calculator.CalculatorService/Sum.
+	Methods map[string]Limit `json:"methods"`
+	// MaxStreams is the number of streams a client can have open at the
+	// same time. Zero means no limit.
+	MaxStreams int `json:"max_streams"`
+}
+
+// DefaultRateLimitConfig is used when no configuration file is given.
+var DefaultRateLimitConfig = RateLimitConfig{
+	Default:    Limit{Rate: 50, Burst: 100},
+	MaxStreams: 10,
+}
+
+// LoadRateLimitConfig reads a JSON RateLimitConfig from path.
+func LoadRateLimitConfig(path string) (RateLimitConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return RateLimitConfig{}, err
+	}
+
+	var cfg RateLimitConfig
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return RateLimitConfig{}, fmt.Errorf("Cannot parse rate limit config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// RateLimiter limits the rate of calls and the number of concurrent
+// streams of each client. Clients are identified by the common name of
+// their TLS certificate when they present one, by their IP address
+// otherwise.
+type RateLimiter struct {
+	cfg RateLimitConfig
+
+	mu        sync.Mutex
+	clients   map[string]*clientState
+	lastSweep time.Time
+}
+
+type clientState struct {
+	limiters map[string]*rate.Limiter
+	streams  int
+	lastSeen time.Time
+}
+
+// NewRateLimiter creates a RateLimiter enforcing cfg.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:       cfg,
+		clients:   map[string]*clientState{},
+		lastSweep: time.Now(),
+	}
+}
+
+// NewRateLimiterFromEnv creates a RateLimiter configured by the JSON file
+// named by the RATE_LIMIT_CONFIG environment variable, or by
+// DefaultRateLimitConfig when it is not set.
+func NewRateLimiterFromEnv() (*RateLimiter, error) {
+	path := os.Getenv("RATE_LIMIT_CONFIG")
+	if path == "" {
+		return NewRateLimiter(DefaultRateLimitConfig), nil
+	}
+
+	cfg, err := LoadRateLimitConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRateLimiter(cfg), nil
+}
+
+// UnaryServerInterceptor rejects the calls exceeding the rate limit of the
+// client with ResourceExhausted.
+func (rl *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := rl.allow(ctx, clientKey(ctx), info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the streams exceeding the rate limit or
+// the number of concurrent streams of the client with ResourceExhausted.
+func (rl *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		key := clientKey(ctx)
+
+		if err := rl.allow(ctx, key, info.FullMethod); err != nil {
+			return err
+		}
+		if err := rl.openStream(key); err != nil {
+			return err
+		}
+		defer rl.closeStream(key)
+
+		return handler(srv, ss)
+	}
+}
+
+func (rl *RateLimiter) allow(ctx context.Context, key string, method string) error {
+	limit, ok := rl.cfg.Methods[method]
+	if !ok {
+		limit = rl.cfg.Default
+	}
+	if limit.Rate <= 0 {
+		return nil
+	}
+
+	rl.mu.Lock()
+	c := rl.client(key)
+	lim, ok := c.limiters[method]
+	if !ok {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = int(math.Ceil(limit.Rate))
+		}
+		lim = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+		c.limiters[method] = lim
+	}
+	rl.mu.Unlock()
+
+	r := lim.Reserve()
+	if !r.OK() {
+		return grpcerr.New(codes.ResourceExhausted, grpcerr.ReasonRateLimited, fmt.Sprintf("Rate limit exceeded for %s", method), nil)
+	}
+	if delay := r.Delay(); delay > 0 {
+		r.Cancel()
+		seconds := int(math.Ceil(delay.Seconds()))
+		grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterKey, strconv.Itoa(seconds)))
+		return grpcerr.ResourceExhausted(grpcerr.ReasonRateLimited,
+			fmt.Sprintf("Rate limit exceeded for %s, retry after %ds", method, seconds),
+			time.Duration(seconds)*time.Second,
+		)
+	}
+	return nil
+}
+
+func (rl *RateLimiter) openStream(key string) error {
+	if rl.cfg.MaxStreams <= 0 {
+		return nil
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	c := rl.client(key)
+	if c.streams >= rl.cfg.MaxStreams {
+		return grpcerr.New(codes.ResourceExhausted, grpcerr.ReasonRateLimited,
+			fmt.Sprintf("Too many concurrent streams: the limit is %d", rl.cfg.MaxStreams),
+			map[string]string{"max_streams": strconv.Itoa(rl.cfg.MaxStreams)},
+		)
+	}
+	c.streams++
+	return nil
+}
+
+func (rl *RateLimiter) closeStream(key string) {
+	if rl.cfg.MaxStreams <= 0 {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	c := rl.client(key)
+	c.streams--
+}
+
+// client returns the state of the client identified by key, and forgets
+// the clients idle for too long. rl.mu must be held.
+func (rl *RateLimiter) client(key string) *clientState {
+	now := time.Now()
+	if now.Sub(rl.lastSweep) > limiterIdleTimeout {
+		for k, c := range rl.clients {
+			if c.streams == 0 && now.Sub(c.lastSeen) > limiterIdleTimeout {
+				delete(rl.clients, k)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	c, ok := rl.clients[key]
+	if !ok {
+		c = &clientState{limiters: map[string]*rate.Limiter{}}
+		rl.clients[key] = c
+	}
+	c.lastSeen = now
+	return c
+}
+
+// clientKey identifies the caller: the common name of its TLS client
+// certificate if any, its IP address otherwise.
+func clientKey(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			if certs := tlsInfo.State.PeerCertificates; len(certs) > 0 {
+				return "cn:" + certs[0].Subject.CommonName
+			}
+		}
+	}
+
+	addr := peerAddr(ctx)
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
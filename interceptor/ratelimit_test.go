@@ -0,0 +1,177 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// transportStream keeps the trailer set by the interceptors.
+type transportStream struct {
+	method  string
+	trailer metadata.MD
+}
+
+func (s *transportStream) Method() string                  { return s.method }
+func (s *transportStream) SetHeader(md metadata.MD) error  { return nil }
+func (s *transportStream) SendHeader(md metadata.MD) error { return nil }
+func (s *transportStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+// serverStream is a stream of the client of ctx.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+// clientContext returns the context of a call of the client at ip.
+func clientContext(ip string, ts *transportStream) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}})
+	return grpc.NewContextWithServerTransportStream(ctx, ts)
+}
+
+func TestRateLimiterUnary(t *testing.T) {
+	const method = "/calculator.CalculatorService/Sum"
+
+	tests := []struct {
+		name string
+		cfg  RateLimitConfig
+		// calls are the clients calling method, in order
+		calls []string
+		// rejected are the indexes of the calls rejected
+		rejected  []int
+		wantDelay time.Duration
+	}{
+		{
+			name:     "bucket drained",
+			cfg:      RateLimitConfig{Default: Limit{Rate: 0.5, Burst: 2}},
+			calls:    []string{"10.0.0.1", "10.0.0.1", "10.0.0.1"},
+			rejected: []int{2},
+			// a token every 2s
+			wantDelay: 2 * time.Second,
+		},
+		{
+			name:      "delay rounded up to the second",
+			cfg:       RateLimitConfig{Default: Limit{Rate: 4, Burst: 1}},
+			calls:     []string{"10.0.0.1", "10.0.0.1"},
+			rejected:  []int{1},
+			wantDelay: time.Second,
+		},
+		{
+			name:      "burst from the rate",
+			cfg:       RateLimitConfig{Default: Limit{Rate: 1.5}},
+			calls:     []string{"10.0.0.1", "10.0.0.1", "10.0.0.1"},
+			rejected:  []int{2},
+			wantDelay: time.Second,
+		},
+		{
+			name:  "one bucket by client",
+			cfg:   RateLimitConfig{Default: Limit{Rate: 0.5, Burst: 1}},
+			calls: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:      "limit of the method",
+			cfg:       RateLimitConfig{Default: Limit{Rate: 100, Burst: 100}, Methods: map[string]Limit{method: {Rate: 0.1, Burst: 1}}},
+			calls:     []string{"10.0.0.1", "10.0.0.1"},
+			rejected:  []int{1},
+			wantDelay: 10 * time.Second,
+		},
+		{
+			name:  "no limit",
+			cfg:   RateLimitConfig{Default: Limit{Burst: 1}},
+			calls: []string{"10.0.0.1", "10.0.0.1", "10.0.0.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := NewRateLimiter(tt.cfg).UnaryServerInterceptor()
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+			for i, ip := range tt.calls {
+				ts := &transportStream{method: method}
+				_, err := interceptor(clientContext(ip, ts), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+
+				rejected := len(tt.rejected) > 0 && tt.rejected[0] == i
+				if !rejected {
+					if err != nil {
+						t.Fatalf("call %d: %v", i, err)
+					}
+					continue
+				}
+				tt.rejected = tt.rejected[1:]
+
+				if status.Code(err) != codes.ResourceExhausted || grpcerr.Reason(err) != grpcerr.ReasonRateLimited {
+					t.Fatalf("call %d: error = %v, want ResourceExhausted", i, err)
+				}
+				if delay, ok := grpcerr.RetryDelay(err); !ok || delay != tt.wantDelay {
+					t.Errorf("call %d: retry delay = %v, want %v", i, delay, tt.wantDelay)
+				}
+				want := strconv.Itoa(int(tt.wantDelay.Seconds()))
+				if got := ts.trailer.Get(RetryAfterKey); len(got) != 1 || got[0] != want {
+					t.Errorf("call %d: %s trailer = %v, want %s", i, RetryAfterKey, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterStreams(t *testing.T) {
+	const method = "/calculator.CalculatorService/ComputeAverage"
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name string
+		// err is the error of the first stream
+		err error
+	}{
+		{name: "stream ended"},
+		{name: "stream failed", err: errHandler},
+		{name: "stream canceled", err: status.Error(codes.Canceled, "canceled")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := NewRateLimiter(RateLimitConfig{MaxStreams: 1}).StreamServerInterceptor()
+			info := &grpc.StreamServerInfo{FullMethod: method}
+			stream := func(ip string) grpc.ServerStream {
+				return &serverStream{ctx: clientContext(ip, &transportStream{method: method})}
+			}
+			ok := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+
+			err := interceptor(nil, stream("10.0.0.1"), info, func(srv interface{}, ss grpc.ServerStream) error {
+				// the slot of the client is taken while the stream is open
+				if err := interceptor(nil, stream("10.0.0.1"), info, ok); status.Code(err) != codes.ResourceExhausted {
+					t.Errorf("second stream: error = %v, want ResourceExhausted", err)
+				}
+				// but not the slots of the other clients
+				if err := interceptor(nil, stream("10.0.0.2"), info, ok); err != nil {
+					t.Errorf("stream of another client: %v", err)
+				}
+				return tt.err
+			})
+			if err != tt.err {
+				t.Fatalf("first stream: error = %v, want %v", err, tt.err)
+			}
+
+			// the slot is released once the stream returns
+			if err := interceptor(nil, stream("10.0.0.1"), info, ok); err != nil {
+				t.Errorf("stream after the first one: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package interceptor
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/grpc"
+)
+
+// Panics counts the panics recovered by the interceptors, by method. It is
+// published with expvar as grpc_server_panics_total.
+var Panics = expvar.NewMap("grpc_server_panics_total")
+
+// UnaryServerRecovery converts a panic in a unary handler to an Internal
+// error instead of crashing the server.
+func UnaryServerRecovery(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, log, info.FullMethod, r)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecovery converts a panic in a streaming handler to an
+// Internal error instead of crashing the server.
+func StreamServerRecovery(log *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), log, info.FullMethod, r)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, log *slog.Logger, method string, r interface{}) error {
+	Panics.Add(method, 1)
+	log.LogAttrs(ctx, slog.LevelError, "recovered from panic",
+		slog.String("method", method),
+		slog.String("request_id", RequestIDFromContext(ctx)),
+		slog.Any("panic", r),
+		slog.String("stack", string(debug.Stack())),
+	)
+	return grpcerr.Internal("Internal error")
+}
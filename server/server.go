@@ -0,0 +1,157 @@
+// Package server runs a grpc.Server hosting a set of services, with the
+// interceptors, TLS, reflection and health checking shared by the server
+// binaries.
+package server
+
+import (
+	"log"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+
+	"github.com/pjserol/tuto-grpc-go/apidocs"
+	"github.com/pjserol/tuto-grpc-go/httpmux"
+	"github.com/pjserol/tuto-grpc-go/interceptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// DefaultAddr is the address the servers listen on by default.
+const DefaultAddr = "0.0.0.0:50051"
+
+// The TLS certificate and key of the servers, see ssl/instructions.sh.
+const (
+	CertFile = "ssl/server.crt"
+	KeyFile  = "ssl/server.pem"
+)
+
+// Service is a gRPC service hosted by the server.
+type Service struct {
+	// Name identifies the service in the logs, e.g. "greet".
+	Name string
+	// Register registers the implementation of the service on s.
+	Register func(s *grpc.Server)
+	// Connect builds the Connect handler of the service, see httpmux.
+	Connect httpmux.ConnectService
+	// Redact are the fields of the messages of the service redacted from
+	// the logs.
+	Redact []string
+}
+
+// Options configures the server.
+type Options struct {
+	// Addr is the address to listen on, DefaultAddr when empty.
+	Addr string
+	// TLS serves with CertFile and KeyFile.
+	TLS bool
+}
+
+// TLSFromEnv reports whether the servers use TLS: always but when the
+// Environment environment variable is "local".
+func TLSFromEnv() bool {
+	return os.Getenv("Environment") != "local"
+}
+
+// Run serves the services until the process receives an interrupt signal.
+// The server is configured by opts and by the environment variables of
+// the interceptor, httpmux and apidocs packages.
+func Run(opts Options, services ...Service) error {
+	if opts.Addr == "" {
+		opts.Addr = DefaultAddr
+	}
+
+	var redact []string
+	for _, service := range services {
+		redact = append(redact, service.Redact...)
+	}
+	logger := interceptor.NewLoggerFromEnv(redact...)
+	slog.SetDefault(logger.Slog())
+
+	limiter, err := interceptor.NewRateLimiterFromEnv()
+	if err != nil {
+		return err
+	}
+
+	// with gRPC-Web or Connect, the TLS is terminated by the HTTP server
+	web := httpmux.ConfigFromEnv()
+	var certFile, keyFile string
+	grpcOpts := []grpc.ServerOption{}
+	if opts.TLS {
+		certFile, keyFile = CertFile, KeyFile
+		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		if !web.Enabled() {
+			grpcOpts = append(grpcOpts, grpc.Creds(creds))
+		}
+	}
+
+	grpcOpts = append(grpcOpts,
+		grpc.ChainUnaryInterceptor(
+			logger.UnaryServerInterceptor(),
+			interceptor.UnaryServerRecovery(logger.Slog()),
+			limiter.UnaryServerInterceptor(),
+			interceptor.UnaryServerValidation(),
+		),
+		grpc.ChainStreamInterceptor(
+			logger.StreamServerInterceptor(),
+			interceptor.StreamServerRecovery(logger.Slog()),
+			limiter.StreamServerInterceptor(),
+			interceptor.StreamServerValidation(),
+		),
+	)
+
+	s := grpc.NewServer(grpcOpts...)
+	interceptor.ServeMetricsFromEnv(logger.Slog())
+	apidocs.ServeFromEnv(logger.Slog())
+
+	var connect []httpmux.ConnectService
+	for _, service := range services {
+		service.Register(s)
+		if service.Connect != nil {
+			connect = append(connect, service.Connect)
+		}
+	}
+
+	// Register reflection service on gRPC server.
+	reflection.Register(s)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	for name := range s.GetServiceInfo() {
+		healthServer.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	lis, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Serving on %s", opts.Addr)
+		errCh <- httpmux.Serve(s, lis, web, certFile, keyFile, connect...)
+	}()
+
+	// Wait for Control C to exit
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+
+	// Block until a signal is received, or the server fails
+	select {
+	case err := <-errCh:
+		return err
+	case <-ch:
+	}
+
+	log.Println("Stopping the server.")
+	healthServer.Shutdown()
+	s.Stop()
+	lis.Close()
+	return <-errCh
+}
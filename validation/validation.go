@@ -0,0 +1,222 @@
+// Package validation checks the messages received by the servers against
+// declarative rules registered by message type.
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pjserol/tuto-grpc-go/grpcerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Rule constrains the value of a field. The zero value of each constraint
+// disables it.
+type Rule struct {
+	// Required rejects the zero value of the field: an unset message, an
+	// empty string or list, or a zero number.
+	Required bool
+	// MinLen and MaxLen bound the number of characters of a string, the
+	// number of bytes of a bytes field, or the number of items of a list.
+	MinLen int
+	MaxLen int
+	// Pattern is a regular expression a non empty string must match.
+	Pattern string
+	// Min and Max bound a number.
+	Min *float64
+	Max *float64
+}
+
+// Rules are the rules of a message, by field path. A path is made of
+// proto field names separated by dots, e.g. "greeting.fist_name". The
+// rules of the fields inside an unset message are only checked for the
+// Required ones.
+type Rules map[string]Rule
+
+// Bound returns a pointer to v, to set Rule.Min and Rule.Max.
+func Bound(v float64) *float64 {
+	return &v
+}
+
+type compiledRule struct {
+	Rule
+	path    []string
+	pattern *regexp.Regexp
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[protoreflect.FullName][]compiledRule{}
+)
+
+// Register sets the rules of the type of msg. It panics if a path does not
+// exist or a pattern does not compile, so it is meant to be called from an
+// init function.
+func Register(msg proto.Message, rules Rules) {
+	desc := msg.ProtoReflect().Descriptor()
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for path, rule := range rules {
+		c := compiledRule{Rule: rule, path: strings.Split(path, ".")}
+		if err := checkPath(desc, c.path); err != nil {
+			panic(fmt.Sprintf("validation: %s: %v", desc.FullName(), err))
+		}
+		if rule.Pattern != "" {
+			c.pattern = regexp.MustCompile(rule.Pattern)
+		}
+		compiled = append(compiled, c)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	registry[desc.FullName()] = compiled
+}
+
+func checkPath(desc protoreflect.MessageDescriptor, path []string) error {
+	for i, name := range path {
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return fmt.Errorf("unknown field %q", strings.Join(path[:i+1], "."))
+		}
+		if i < len(path)-1 {
+			if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+				return fmt.Errorf("field %q is not a message", strings.Join(path[:i+1], "."))
+			}
+			desc = fd.Message()
+		}
+	}
+	return nil
+}
+
+// Violations returns the field violations of msg, sorted by field path.
+func Violations(msg proto.Message) []*errdetails.BadRequest_FieldViolation {
+	m := msg.ProtoReflect()
+
+	mu.RLock()
+	rules := registry[m.Descriptor().FullName()]
+	mu.RUnlock()
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, rule := range rules {
+		if description := rule.check(m); description != "" {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       strings.Join(rule.path, "."),
+				Description: description,
+			})
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].GetField() < violations[j].GetField()
+	})
+	return violations
+}
+
+// Validate returns an InvalidArgument error with a BadRequest detail
+// listing the violations of msg, or nil when msg is valid.
+func Validate(msg proto.Message) error {
+	violations := Violations(msg)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(violations))
+	for i, v := range violations {
+		fields[i] = v.GetField()
+	}
+	return grpcerr.New(codes.InvalidArgument, grpcerr.ReasonInvalidArgument,
+		fmt.Sprintf("Invalid %s: %s", strings.Join(fields, ", "), violations[0].GetDescription()),
+		map[string]string{"field": fields[0]},
+		&errdetails.BadRequest{FieldViolations: violations},
+	)
+}
+
+// check returns the description of the violation of the rule by m, or an
+// empty string.
+func (r *compiledRule) check(m protoreflect.Message) string {
+	for _, name := range r.path[:len(r.path)-1] {
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if !m.Has(fd) {
+			if r.Required {
+				return "must be set"
+			}
+			return ""
+		}
+		m = m.Get(fd).Message()
+	}
+
+	fd := m.Descriptor().Fields().ByName(protoreflect.Name(r.path[len(r.path)-1]))
+	if !m.Has(fd) {
+		if r.Required {
+			switch {
+			case fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.StringKind || fd.Kind() == protoreflect.BytesKind:
+				return "must not be empty"
+			case fd.Message() != nil:
+				return "must be set"
+			default:
+				return "must not be zero"
+			}
+		}
+		// the other constraints apply to the default value
+		if r.MinLen <= 0 && r.Min == nil && r.Max == nil {
+			return ""
+		}
+	}
+	v := m.Get(fd)
+
+	switch {
+	case fd.IsList():
+		return r.checkLen(v.List().Len(), "items")
+	case fd.IsMap():
+		return r.checkLen(v.Map().Len(), "items")
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		s := v.String()
+		if msg := r.checkLen(utf8.RuneCountInString(s), "characters"); msg != "" {
+			return msg
+		}
+		if r.pattern != nil && s != "" && !r.pattern.MatchString(s) {
+			return fmt.Sprintf("must match %s", r.Pattern)
+		}
+	case protoreflect.BytesKind:
+		return r.checkLen(len(v.Bytes()), "bytes")
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return r.checkRange(float64(v.Int()))
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return r.checkRange(float64(v.Uint()))
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return r.checkRange(v.Float())
+	case protoreflect.EnumKind:
+		return r.checkRange(float64(v.Enum()))
+	}
+	return ""
+}
+
+func (r *compiledRule) checkLen(n int, unit string) string {
+	if r.MinLen > 0 && n < r.MinLen {
+		return fmt.Sprintf("must be at least %d %s", r.MinLen, unit)
+	}
+	if r.MaxLen > 0 && n > r.MaxLen {
+		return fmt.Sprintf("must be at most %d %s", r.MaxLen, unit)
+	}
+	return ""
+}
+
+func (r *compiledRule) checkRange(n float64) string {
+	if r.Min != nil && n < *r.Min {
+		return fmt.Sprintf("must be greater than or equal to %v", *r.Min)
+	}
+	if r.Max != nil && n > *r.Max {
+		return fmt.Sprintf("must be less than or equal to %v", *r.Max)
+	}
+	return ""
+}